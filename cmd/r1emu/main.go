@@ -0,0 +1,95 @@
+// Command r1emu runs a local emulator of the Ratio1 CStore and R1FS REST
+// managers. Point EE_CHAINSTORE_API_URL and EE_R1FS_API_URL at the listen
+// address to exercise the SDK clients without a live edge node:
+//
+//	go run ./cmd/r1emu -addr 127.0.0.1:8787 -data-dir ./.r1emu
+//	export EE_CHAINSTORE_API_URL=http://127.0.0.1:8787
+//	export EE_R1FS_API_URL=http://127.0.0.1:8787
+//
+// Co-located clients can skip TCP with -addr unix:///tmp/r1emu.sock and
+// EE_*_API_URL=unix:///tmp/r1emu.sock.
+//
+// When -data-dir is set, state is persisted after every write and reloaded on
+// start-up. R1FS content is stored as one file per CID next to a metadata
+// snapshot.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8787", "listen address (host:port or unix:///path.sock) for the emulated CStore and R1FS APIs")
+	dataDir := flag.String("data-dir", "", "directory used to persist state across restarts (in-memory when empty)")
+	flag.Parse()
+
+	srv, err := newServer(*dataDir)
+	if err != nil {
+		log.Fatalf("initialise emulator: %v", err)
+	}
+	defer srv.Close()
+
+	ln, err := listen(*addr)
+	if err != nil {
+		log.Fatalf("listen: %v", err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           srv,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if strings.HasPrefix(*addr, "unix://") {
+		log.Printf("r1emu listening on %s", *addr)
+	} else {
+		log.Printf("r1emu listening on http://%s", *addr)
+	}
+	if err := httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("serve: %v", err)
+	}
+}
+
+// listen opens a TCP listener, or a unix socket for unix:// addresses. A stale
+// socket left by a previous run is removed first; any other file at the path
+// is left alone and reported as an error.
+func listen(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, "unix://")
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	info, err := os.Lstat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	case info.Mode().Type() != fs.ModeSocket:
+		return nil, fmt.Errorf("%s exists and is not a unix socket", path)
+	default:
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,572 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore/memstore"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/memfs"
+)
+
+const (
+	cstoreSnapshotFile = "cstore.json"
+	r1fsSnapshotFile   = "r1fs.json"
+	r1fsBlobDir        = "r1fs" // one file per CID, named after it
+	maxMultipartMemory = 32 << 20
+)
+
+// server emulates the CStore and R1FS FastAPI managers on a single handler.
+type server struct {
+	cstore  *memstore.Store
+	r1fs    *memfs.Store
+	dataDir string
+
+	persistMu sync.Mutex
+	mux       *http.ServeMux
+}
+
+// newServer builds an emulator. When dataDir is non-empty, existing snapshots
+// are loaded from it and every write is persisted back: CStore as a single
+// snapshot, R1FS as a metadata snapshot plus one content file per CID.
+func newServer(dataDir string) (*server, error) {
+	s := &server{
+		cstore:  memstore.New(),
+		r1fs:    memfs.New(),
+		dataDir: dataDir,
+		mux:     http.NewServeMux(),
+	}
+	if dataDir != "" {
+		if err := os.MkdirAll(filepath.Join(dataDir, r1fsBlobDir), 0o755); err != nil {
+			return nil, fmt.Errorf("create data dir: %w", err)
+		}
+		if err := s.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mux.HandleFunc("/get", s.handleGet)
+	s.mux.HandleFunc("/set", s.handleSet)
+	s.mux.HandleFunc("/hget", s.handleHGet)
+	s.mux.HandleFunc("/hset", s.handleHSet)
+	s.mux.HandleFunc("/hgetall", s.handleHGetAll)
+	s.mux.HandleFunc("/get_status", s.handleGetStatus)
+
+	s.mux.HandleFunc("/add_file", s.handleAddFile)
+	s.mux.HandleFunc("/add_file_base64", s.handleAddFileBase64)
+	s.mux.HandleFunc("/get_file", s.handleGetFile)
+	s.mux.HandleFunc("/get_file_base64", s.handleGetFileBase64)
+	s.mux.HandleFunc("/add_json", s.handleAddDocument(s.r1fs.AddJSON))
+	s.mux.HandleFunc("/add_pickle", s.handleAddDocument(s.r1fs.AddPickle))
+	s.mux.HandleFunc("/add_yaml", s.handleAddDocument(s.r1fs.AddYAML))
+	s.mux.HandleFunc("/get_yaml", s.handleGetYAML)
+	s.mux.HandleFunc("/calculate_json_cid", s.handleCalculateCID(s.r1fs.CalculateJSONCID))
+	s.mux.HandleFunc("/calculate_pickle_cid", s.handleCalculateCID(s.r1fs.CalculatePickleCID))
+	s.mux.HandleFunc("/delete_file", s.handleDeleteFile)
+	s.mux.HandleFunc("/delete_files", s.handleDeleteFiles)
+	return s, nil
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close releases files materialised by /get_file.
+func (s *server) Close() error {
+	return s.r1fs.Close()
+}
+
+func (s *server) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	data, err := s.cstore.Get(r.Context(), r.URL.Query().Get("key"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeResult(w, rawOrNull(data))
+}
+
+func (s *server) handleSet(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		Key   string          `json:"key"`
+		Value json.RawMessage `json:"value"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Key) == "" {
+		writeError(w, http.StatusUnprocessableEntity, errors.New("key is required"))
+		return
+	}
+	if err := s.cstore.Set(r.Context(), req.Key, req.Value, nil); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.persistAndRespond(w, s.persistCStore, true)
+}
+
+func (s *server) handleHGet(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	q := r.URL.Query()
+	data, err := s.cstore.HGet(r.Context(), q.Get("hkey"), q.Get("key"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeResult(w, rawOrNull(data))
+}
+
+func (s *server) handleHSet(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		HashKey string          `json:"hkey"`
+		Key     string          `json:"key"`
+		Value   json.RawMessage `json:"value"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.HashKey) == "" || strings.TrimSpace(req.Key) == "" {
+		writeError(w, http.StatusUnprocessableEntity, errors.New("hkey and key are required"))
+		return
+	}
+	if err := s.cstore.HSet(r.Context(), req.HashKey, req.Key, req.Value, nil); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.persistAndRespond(w, s.persistCStore, true)
+}
+
+func (s *server) handleHGetAll(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	data, err := s.cstore.HGetAll(r.Context(), r.URL.Query().Get("hkey"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeResult(w, rawOrNull(data))
+}
+
+func (s *server) handleGetStatus(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	data, err := s.cstore.GetStatus(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeResult(w, rawOrNull(data))
+}
+
+func (s *server) handleAddFile(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	if err := r.ParseMultipartForm(maxMultipartMemory); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	opts := &r1fs.DataOptions{Filename: header.Filename}
+	if raw := strings.TrimSpace(r.FormValue("body_json")); raw != "" {
+		var meta documentRequest
+		if err := json.Unmarshal([]byte(raw), &meta); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		opts.Secret = meta.Secret
+		opts.Nonce = meta.Nonce
+		if strings.TrimSpace(meta.Fn) != "" {
+			opts.Filename = meta.Fn
+		}
+	}
+	cid, err := s.r1fs.AddFile(r.Context(), data, opts)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.persistAndRespond(w, s.persistR1FS, map[string]any{"message": "File uploaded successfully", "cid": cid})
+}
+
+func (s *server) handleAddFileBase64(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		FileBase64 string `json:"file_base64_str"`
+		Filename   string `json:"filename"`
+		FilePath   string `json:"file_path"`
+		Secret     string `json:"secret"`
+		Nonce      *int   `json:"nonce"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(req.FileBase64)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	cid, err := s.r1fs.AddFileBase64(r.Context(), data, &r1fs.DataOptions{
+		Filename: req.Filename,
+		FilePath: req.FilePath,
+		Secret:   req.Secret,
+		Nonce:    req.Nonce,
+	})
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	s.persistAndRespond(w, s.persistR1FS, map[string]any{"cid": cid})
+}
+
+func (s *server) handleGetFile(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	q := r.URL.Query()
+	loc, err := s.r1fs.GetFile(r.Context(), q.Get("cid"), q.Get("secret"))
+	if err != nil {
+		writeLookupError(w, err)
+		return
+	}
+	writeResult(w, map[string]any{"file_path": loc.Path, "meta": loc.Meta})
+}
+
+func (s *server) handleGetFileBase64(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		CID    string `json:"cid"`
+		Secret string `json:"secret"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	data, name, err := s.r1fs.GetFileBase64(r.Context(), req.CID, req.Secret)
+	if err != nil {
+		writeLookupError(w, err)
+		return
+	}
+	writeResult(w, map[string]any{
+		"file_base64_str": base64.StdEncoding.EncodeToString(data),
+		"filename":        name,
+	})
+}
+
+// documentRequest mirrors the JSON body shared by the add_* and calculate_*
+// document endpoints.
+type documentRequest struct {
+	Data     json.RawMessage `json:"data"`
+	Fn       string          `json:"fn"`
+	FilePath string          `json:"file_path"`
+	Secret   string          `json:"secret"`
+	Nonce    *int            `json:"nonce"`
+}
+
+func (d documentRequest) options() *r1fs.DataOptions {
+	return &r1fs.DataOptions{Filename: d.Fn, FilePath: d.FilePath, Secret: d.Secret, Nonce: d.Nonce}
+}
+
+type addDocumentFunc func(ctx context.Context, data any, opts *r1fs.DataOptions) (string, error)
+
+func (s *server) handleAddDocument(add addDocumentFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !allowMethod(w, r, http.MethodPost) {
+			return
+		}
+		var req documentRequest
+		if !decodeBody(w, r, &req) {
+			return
+		}
+		if len(req.Data) == 0 {
+			writeError(w, http.StatusUnprocessableEntity, errors.New("data is required"))
+			return
+		}
+		cid, err := add(r.Context(), req.Data, req.options())
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		s.persistAndRespond(w, s.persistR1FS, map[string]any{"cid": cid})
+	}
+}
+
+type calculateCIDFunc func(ctx context.Context, data any, nonce int, opts *r1fs.DataOptions) (string, error)
+
+func (s *server) handleCalculateCID(calc calculateCIDFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !allowMethod(w, r, http.MethodPost) {
+			return
+		}
+		var req documentRequest
+		if !decodeBody(w, r, &req) {
+			return
+		}
+		if len(req.Data) == 0 {
+			writeError(w, http.StatusUnprocessableEntity, errors.New("data is required"))
+			return
+		}
+		nonce := 0
+		if req.Nonce != nil {
+			nonce = *req.Nonce
+		}
+		cid, err := calc(r.Context(), req.Data, nonce, req.options())
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		writeResult(w, map[string]any{"cid": cid})
+	}
+}
+
+func (s *server) handleGetYAML(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	q := r.URL.Query()
+	data, err := s.r1fs.GetYAML(r.Context(), q.Get("cid"), q.Get("secret"))
+	if err != nil {
+		writeLookupError(w, err)
+		return
+	}
+	writeResult(w, rawOrNull(data))
+}
+
+// deleteRequest mirrors the flags accepted by delete_file and delete_files.
+type deleteRequest struct {
+	UnpinRemote       *bool `json:"unpin_remote"`
+	RunGC             *bool `json:"run_gc"`
+	RunGCAfterAll     *bool `json:"run_gc_after_all"`
+	CleanupLocalFiles *bool `json:"cleanup_local_files"`
+}
+
+func (d deleteRequest) options(bulk bool) *r1fs.DeleteOptions {
+	opts := &r1fs.DeleteOptions{UnpinRemote: d.UnpinRemote, RunGC: d.RunGC, CleanupLocalFiles: d.CleanupLocalFiles}
+	if bulk {
+		opts.RunGC = d.RunGCAfterAll
+	}
+	return opts
+}
+
+func (s *server) handleDeleteFile(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		CID string `json:"cid"`
+		deleteRequest
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	res, err := s.r1fs.DeleteFile(r.Context(), req.CID, req.options(false))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.persistAndRespond(w, s.persistR1FS, res)
+}
+
+func (s *server) handleDeleteFiles(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		CIDs []string `json:"cids"`
+		deleteRequest
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	res, err := s.r1fs.DeleteFiles(r.Context(), req.CIDs, req.options(true))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.persistAndRespond(w, s.persistR1FS, res)
+}
+
+func (s *server) persistAndRespond(w http.ResponseWriter, persist func() error, result any) {
+	if s.dataDir != "" {
+		s.persistMu.Lock()
+		err := persist()
+		s.persistMu.Unlock()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	writeResult(w, result)
+}
+
+func (s *server) load() error {
+	if err := loadSnapshot(filepath.Join(s.dataDir, cstoreSnapshotFile), s.cstore.Restore); err != nil {
+		return err
+	}
+	return loadSnapshot(filepath.Join(s.dataDir, r1fsSnapshotFile), func(r io.Reader) error {
+		return s.r1fs.Restore(r, func(cid string) ([]byte, error) {
+			return os.ReadFile(filepath.Join(s.dataDir, r1fsBlobDir, cid))
+		})
+	})
+}
+
+func (s *server) persistCStore() error {
+	return writeSnapshot(filepath.Join(s.dataDir, cstoreSnapshotFile), s.cstore.Snapshot)
+}
+
+// persistR1FS writes the content of CIDs that have no file yet, then the
+// metadata snapshot, then removes files of deleted CIDs, so the snapshot never
+// names a CID whose content is missing. Content is addressed by CID, so
+// existing files are never rewritten.
+func (s *server) persistR1FS() error {
+	dir := filepath.Join(s.dataDir, r1fsBlobDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("list content files: %w", err)
+	}
+	stale := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		stale[e.Name()] = true
+	}
+	for _, cid := range s.r1fs.CIDs() {
+		if stale[cid] {
+			delete(stale, cid)
+			continue
+		}
+		if filepath.Base(cid) != cid {
+			return fmt.Errorf("cid %q is not a valid file name", cid)
+		}
+		data, ok := s.r1fs.Content(cid)
+		if !ok {
+			continue
+		}
+		err := writeSnapshot(filepath.Join(dir, cid), func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := writeSnapshot(filepath.Join(s.dataDir, r1fsSnapshotFile), s.r1fs.Snapshot); err != nil {
+		return err
+	}
+	for name := range stale {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove content file: %w", err)
+		}
+	}
+	return nil
+}
+
+func loadSnapshot(path string, restore func(io.Reader) error) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open snapshot: %w", err)
+	}
+	defer f.Close()
+	return restore(f)
+}
+
+// writeSnapshot writes through a temporary file so a crash never leaves a
+// truncated snapshot behind.
+func writeSnapshot(path string, snapshot func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := snapshot(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace snapshot: %w", err)
+	}
+	return nil
+}
+
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, out any) bool {
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(out); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return false
+	}
+	return true
+}
+
+func rawOrNull(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(data)
+}
+
+func writeResult(w http.ResponseWriter, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"result": result})
+}
+
+// writeError mirrors FastAPI's {"detail": ...} error body.
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"detail": err.Error()})
+}
+
+func writeLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, r1fs.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusForbidden, err)
+}
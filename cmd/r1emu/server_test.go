@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+)
+
+type counter struct {
+	Count int `json:"count"`
+}
+
+func TestEmulatorServesClients(t *testing.T) {
+	emu, err := newServer("")
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	defer emu.Close()
+	srv := newLocalHTTPServer(t, emu)
+	defer srv.Close()
+
+	ctx := context.Background()
+	cs, err := cstore.New(srv.URL)
+	if err != nil {
+		t.Fatalf("cstore.New: %v", err)
+	}
+	if err := cs.Set(ctx, "jobs:1", counter{Count: 1}, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	var got counter
+	if item, err := cs.Get(ctx, "jobs:1", &got); err != nil || item == nil || got.Count != 1 {
+		t.Fatalf("Get: item=%#v value=%#v err=%v", item, got, err)
+	}
+	if item, err := cs.Get(ctx, "missing", nil); err != nil || item != nil {
+		t.Fatalf("Get missing: item=%#v err=%v", item, err)
+	}
+	if err := cs.HSet(ctx, "jobs", "1", counter{Count: 2}, nil); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+	all, err := cs.HGetAll(ctx, "jobs")
+	if err != nil || len(all) != 1 || all[0].Field != "1" {
+		t.Fatalf("HGetAll: %#v err=%v", all, err)
+	}
+	status, err := cs.GetStatus(ctx)
+	if err != nil || status == nil || len(status.Keys) != 1 {
+		t.Fatalf("GetStatus: %#v err=%v", status, err)
+	}
+
+	fs, err := r1fs.New(srv.URL)
+	if err != nil {
+		t.Fatalf("r1fs.New: %v", err)
+	}
+	cid, err := fs.AddFile(ctx, strings.NewReader("hello"), &r1fs.DataOptions{Filename: "hello.txt", Secret: "s3"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	data, name, err := fs.GetFileBase64(ctx, cid, "s3")
+	if err != nil || string(data) != "hello" || name != "hello.txt" {
+		t.Fatalf("GetFileBase64: %q name=%q err=%v", data, name, err)
+	}
+	loc, err := fs.GetFile(ctx, cid, "s3")
+	if err != nil || loc.Filename != "hello.txt" || loc.Path == "" {
+		t.Fatalf("GetFile: %#v err=%v", loc, err)
+	}
+	b64CID, err := fs.AddFileBase64(ctx, strings.NewReader("hello"), &r1fs.DataOptions{FilePath: "/tmp/hello.txt"})
+	if err != nil {
+		t.Fatalf("AddFileBase64: %v", err)
+	}
+
+	yamlCID, err := fs.AddYAML(ctx, map[string]any{"name": "ratio1"}, nil)
+	if err != nil {
+		t.Fatalf("AddYAML: %v", err)
+	}
+	var doc map[string]any
+	if _, err := fs.GetYAML(ctx, yamlCID, "", &doc); err != nil || doc["name"] != "ratio1" {
+		t.Fatalf("GetYAML: %#v err=%v", doc, err)
+	}
+	if _, err := fs.GetYAML(ctx, "QmMissing", "", nil); err == nil {
+		t.Fatalf("expected error for missing YAML document")
+	}
+
+	nonce := 3
+	jsonCID, err := fs.AddJSON(ctx, map[string]any{"a": 1}, &r1fs.DataOptions{Nonce: &nonce})
+	if err != nil {
+		t.Fatalf("AddJSON: %v", err)
+	}
+	calc, err := fs.CalculateJSONCID(ctx, map[string]any{"a": 1}, nonce, nil)
+	if err != nil || calc != jsonCID {
+		t.Fatalf("CalculateJSONCID: %s want %s err=%v", calc, jsonCID, err)
+	}
+	if _, err := fs.AddPickle(ctx, map[string]any{"a": 1}, nil); err != nil {
+		t.Fatalf("AddPickle: %v", err)
+	}
+	if _, err := fs.CalculatePickleCID(ctx, map[string]any{"a": 1}, 1, nil); err != nil {
+		t.Fatalf("CalculatePickleCID: %v", err)
+	}
+
+	del, err := fs.DeleteFile(ctx, jsonCID, nil)
+	if err != nil || !del.Success {
+		t.Fatalf("DeleteFile: %#v err=%v", del, err)
+	}
+	bulk, err := fs.DeleteFiles(ctx, []string{cid, b64CID, jsonCID}, nil)
+	if err != nil || bulk.SuccessCount != 2 || bulk.FailedCount != 1 {
+		t.Fatalf("DeleteFiles: %#v err=%v", bulk, err)
+	}
+}
+
+func TestEmulatorPersistsState(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	first, err := newServer(dir)
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	srv := newLocalHTTPServer(t, first)
+	cs, _ := cstore.New(srv.URL)
+	fs, _ := r1fs.New(srv.URL)
+	if err := cs.Set(ctx, "persisted", counter{Count: 9}, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	cid, err := fs.AddFile(ctx, strings.NewReader("durable"), &r1fs.DataOptions{Filename: "d.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	gone, err := fs.AddFile(ctx, strings.NewReader("short-lived"), &r1fs.DataOptions{Filename: "g.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, r1fsBlobDir, cid)); err != nil || string(data) != "durable" {
+		t.Fatalf("content file: %q err=%v", data, err)
+	}
+	if meta, err := os.ReadFile(filepath.Join(dir, r1fsSnapshotFile)); err != nil || strings.Contains(string(meta), "ZHVyYWJsZQ") {
+		t.Fatalf("metadata snapshot should not hold content: %s err=%v", meta, err)
+	}
+	if _, err := fs.DeleteFile(ctx, gone, nil); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, r1fsBlobDir, gone)); !os.IsNotExist(err) {
+		t.Fatalf("content file of a deleted CID should be removed, stat err=%v", err)
+	}
+	srv.Close()
+	first.Close()
+
+	second, err := newServer(dir)
+	if err != nil {
+		t.Fatalf("newServer reload: %v", err)
+	}
+	defer second.Close()
+	srv = newLocalHTTPServer(t, second)
+	defer srv.Close()
+	cs, _ = cstore.New(srv.URL)
+	fs, _ = r1fs.New(srv.URL)
+
+	var got counter
+	if item, err := cs.Get(ctx, "persisted", &got); err != nil || item == nil || got.Count != 9 {
+		t.Fatalf("Get after reload: item=%#v value=%#v err=%v", item, got, err)
+	}
+	data, _, err := fs.GetFileBase64(ctx, cid, "")
+	if err != nil || string(data) != "durable" {
+		t.Fatalf("GetFileBase64 after reload: %q err=%v", data, err)
+	}
+	if _, _, err := fs.GetFileBase64(ctx, gone, ""); err == nil {
+		t.Fatalf("deleted CID came back after reload")
+	}
+}
+
+func TestEmulatorLookupsAndDeleteOptions(t *testing.T) {
+	emu, err := newServer("")
+	if err != nil {
+		t.Fatalf("newServer: %v", err)
+	}
+	defer emu.Close()
+	srv := newLocalHTTPServer(t, emu)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/get_yaml?cid=QmMissing")
+	if err != nil {
+		t.Fatalf("get_yaml: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("get_yaml for a missing CID returned %d, want 404", resp.StatusCode)
+	}
+
+	ctx := context.Background()
+	fs, err := r1fs.New(srv.URL)
+	if err != nil {
+		t.Fatalf("r1fs.New: %v", err)
+	}
+	var paths []string
+	var cids []string
+	for _, name := range []string{"kept.txt", "cleaned.txt"} {
+		cid, err := fs.AddFile(ctx, strings.NewReader(name), &r1fs.DataOptions{Filename: name})
+		if err != nil {
+			t.Fatalf("AddFile: %v", err)
+		}
+		loc, err := fs.GetFile(ctx, cid, "")
+		if err != nil {
+			t.Fatalf("GetFile: %v", err)
+		}
+		cids = append(cids, cid)
+		paths = append(paths, loc.Path)
+	}
+	keep, cleanup := false, true
+	if _, err := fs.DeleteFile(ctx, cids[0], &r1fs.DeleteOptions{CleanupLocalFiles: &keep}); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := fs.DeleteFiles(ctx, cids[1:], &r1fs.DeleteOptions{CleanupLocalFiles: &cleanup}); err != nil {
+		t.Fatalf("DeleteFiles: %v", err)
+	}
+	if _, err := os.Stat(paths[0]); err != nil {
+		t.Fatalf("local copy removed without cleanup_local_files: %v", err)
+	}
+	if _, err := os.Stat(paths[1]); !os.IsNotExist(err) {
+		t.Fatalf("cleanup_local_files left %s behind, stat err=%v", paths[1], err)
+	}
+}
+
+type testServer struct {
+	URL      string
+	listener net.Listener
+	server   *http.Server
+}
+
+func (s *testServer) Close() {
+	_ = s.server.Shutdown(context.Background())
+	_ = s.listener.Close()
+}
+
+func newLocalHTTPServer(t *testing.T, handler http.Handler) *testServer {
+	t.Helper()
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("network disabled for tests: %v", err)
+	}
+	srv := &http.Server{Handler: handler}
+	ts := &testServer{
+		URL:      "http://" + ln.Addr().String(),
+		listener: ln,
+		server:   srv,
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			t.Logf("test server serve error: %v", err)
+		}
+	}()
+	return ts
+}
+
+func TestListenReplacesOnlyStaleSockets(t *testing.T) {
+	dir := t.TempDir()
+	regular := filepath.Join(dir, "data.json")
+	if err := os.WriteFile(regular, []byte("keep"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if ln, err := listen("unix://" + regular); err == nil {
+		ln.Close()
+		t.Fatalf("expected listen to refuse a regular file")
+	}
+	if data, err := os.ReadFile(regular); err != nil || string(data) != "keep" {
+		t.Fatalf("regular file was modified: %q err=%v", data, err)
+	}
+
+	sock := filepath.Join(dir, "r1emu.sock")
+	stale, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+	ln, err := listen("unix://" + sock)
+	if err != nil {
+		t.Fatalf("listen over a stale socket: %v", err)
+	}
+	ln.Close()
+}
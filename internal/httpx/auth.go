@@ -0,0 +1,210 @@
+package httpx
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator adds credentials to an outbound request. Client.Do calls it
+// for every attempt, after default and per-request headers are applied, so
+// retries carry fresh tokens and signatures. req.GetBody is set when the body
+// is held in memory; streamed bodies leave it nil.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc adapts a function into an Authenticator.
+type AuthenticatorFunc func(req *http.Request) error
+
+// Authenticate calls f(req).
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// WithAuthenticator installs a that is invoked for every request attempt.
+func WithAuthenticator(a Authenticator) Option {
+	return func(c *Client) {
+		c.auth = a
+	}
+}
+
+// TokenSource returns the bearer token to send with a request.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc adapts a function into a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token calls f(ctx).
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticToken returns a TokenSource that always yields token.
+func StaticToken(token string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// CachedToken returns a TokenSource that calls fetch for a token and reuses it
+// until shortly before the expiry fetch reported. A zero expiry never expires.
+func CachedToken(fetch func(ctx context.Context) (token string, expiry time.Time, err error)) TokenSource {
+	return &cachedToken{fetch: fetch, leeway: 30 * time.Second, now: time.Now}
+}
+
+type cachedToken struct {
+	fetch  func(ctx context.Context) (string, time.Time, error)
+	leeway time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func (c *cachedToken) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && (c.expiry.IsZero() || c.now().Add(c.leeway).Before(c.expiry)) {
+		return c.token, nil
+	}
+	token, expiry, err := c.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token, c.expiry = token, expiry
+	return token, nil
+}
+
+// BearerAuth returns an Authenticator that sets "Authorization: Bearer
+// <token>" with a token obtained from src for every attempt.
+func BearerAuth(src TokenSource) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		if src == nil {
+			return errors.New("httpx: token source is nil")
+		}
+		token, err := src.Token(req.Context())
+		if err != nil {
+			return fmt.Errorf("httpx: obtain bearer token: %w", err)
+		}
+		if strings.TrimSpace(token) == "" {
+			return errors.New("httpx: token source returned an empty token")
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// UnsignedPayload is the content hash HMACSigner reports for streamed bodies
+// it cannot hash without consuming them.
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// Default header names used by HMACSigner.
+const (
+	DefaultSignatureHeader   = "X-Signature"
+	DefaultTimestampHeader   = "X-Signature-Timestamp"
+	DefaultKeyIDHeader       = "X-Signature-Key-Id"
+	DefaultContentHashHeader = "X-Content-Sha256"
+)
+
+// HMACSigner signs requests with HMAC-SHA256 over the canonical string
+//
+//	METHOD "\n" PATH "\n" QUERY "\n" TIMESTAMP "\n" CONTENT-SHA256
+//
+// where PATH is the escaped URL path, QUERY the query string with keys
+// sorted, TIMESTAMP the Unix time in seconds and CONTENT-SHA256 the hex
+// SHA-256 of the body, or UnsignedPayload for streamed bodies. The hex
+// signature, timestamp, key ID and content hash are sent as headers.
+type HMACSigner struct {
+	KeyID  string
+	Secret []byte
+
+	// Header names; empty values use the Default*Header constants.
+	SignatureHeader   string
+	TimestampHeader   string
+	KeyIDHeader       string
+	ContentHashHeader string
+
+	// Now overrides the clock, mainly for tests.
+	Now func() time.Time
+}
+
+// Authenticate signs req.
+func (s *HMACSigner) Authenticate(req *http.Request) error {
+	if len(s.Secret) == 0 {
+		return errors.New("httpx: HMAC secret is empty")
+	}
+	contentHash, err := bodySHA256(req)
+	if err != nil {
+		return err
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+
+	req.Header.Set(headerName(s.TimestampHeader, DefaultTimestampHeader), timestamp)
+	req.Header.Set(headerName(s.ContentHashHeader, DefaultContentHashHeader), contentHash)
+	if s.KeyID != "" {
+		req.Header.Set(headerName(s.KeyIDHeader, DefaultKeyIDHeader), s.KeyID)
+	}
+	req.Header.Set(headerName(s.SignatureHeader, DefaultSignatureHeader), s.Sign(req, timestamp, contentHash))
+	return nil
+}
+
+// Sign returns the hex signature of req for the given timestamp and content
+// hash. Servers can use it to verify incoming requests.
+func (s *HMACSigner) Sign(req *http.Request, timestamp string, contentHash string) string {
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		timestamp,
+		contentHash,
+	}, "\n")
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func headerName(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// bodySHA256 hashes the request body through GetBody so the body sent on the
+// wire is left untouched.
+func bodySHA256(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		sum := sha256.Sum256(nil)
+		return hex.EncodeToString(sum[:]), nil
+	}
+	if req.GetBody == nil {
+		return UnsignedPayload, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", fmt.Errorf("httpx: read body for signing: %w", err)
+	}
+	defer closeBody(body)
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return "", fmt.Errorf("httpx: read body for signing: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,177 @@
+package httpx_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ratio1/edge_sdk_go/internal/httpx"
+)
+
+func fastRetries() httpx.RetryPolicy {
+	return httpx.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+}
+
+func TestHMACSignerSignsEveryAttempt(t *testing.T) {
+	var clock int64 = 1_700_000_000
+	signer := &httpx.HMACSigner{
+		KeyID:  "gateway",
+		Secret: []byte("shared"),
+		Now: func() time.Time {
+			clock++
+			return time.Unix(clock, 0)
+		},
+	}
+
+	var (
+		mu         sync.Mutex
+		timestamps []string
+		hashes     []string
+	)
+	url := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		hash := r.Header.Get("X-Content-Sha256")
+		if hash != httpx.UnsignedPayload {
+			sum := sha256.Sum256(body)
+			if hash != hex.EncodeToString(sum[:]) {
+				t.Errorf("content hash %s does not match body", hash)
+			}
+		}
+		ts := r.Header.Get("X-Signature-Timestamp")
+		if got, want := r.Header.Get("X-Signature"), signer.Sign(r, ts, hash); got != want || r.Header.Get("X-Signature-Key-Id") != "gateway" {
+			t.Errorf("bad signature %q, want %q", got, want)
+		}
+		mu.Lock()
+		timestamps = append(timestamps, ts)
+		hashes = append(hashes, hash)
+		first := len(timestamps)%2 == 1
+		mu.Unlock()
+		if first {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	c, err := httpx.NewClient(url, httpx.WithAuthenticator(signer), httpx.WithRetryPolicy(fastRetries()))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	ctx := context.Background()
+	resp, err := c.Do(ctx, &httpx.Request{Method: http.MethodPost, Path: "add_json", Body: bytes.NewReader([]byte(`{"a":1}`))})
+	if err != nil {
+		t.Fatalf("Do buffered: %v", err)
+	}
+	resp.Body.Close()
+	// A reader net/http cannot replay on its own is streamed unsigned.
+	streamed := func() io.Reader { return io.MultiReader(strings.NewReader("streamed")) }
+	resp, err = c.Do(ctx, &httpx.Request{
+		Method:  http.MethodPost,
+		Path:    "add_file",
+		Body:    streamed(),
+		GetBody: func() (io.ReadCloser, error) { return io.NopCloser(streamed()), nil },
+	})
+	if err != nil {
+		t.Fatalf("Do streamed: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(timestamps) != 4 || timestamps[0] == timestamps[1] {
+		t.Fatalf("expected a fresh signature per attempt, got %v", timestamps)
+	}
+	if hashes[0] == httpx.UnsignedPayload || hashes[2] != httpx.UnsignedPayload {
+		t.Fatalf("unexpected content hashes %v", hashes)
+	}
+}
+
+func TestBearerAuthRefreshesTokenOnRetry(t *testing.T) {
+	var issued int
+	tokens := httpx.TokenSourceFunc(func(context.Context) (string, error) {
+		issued++
+		return fmt.Sprintf("token-%d", issued), nil
+	})
+	var seen []string
+	url := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		if len(seen) == 1 {
+			http.Error(w, "expired", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	c, err := httpx.NewClient(url, httpx.WithAuthenticator(httpx.BearerAuth(tokens)), httpx.WithRetryPolicy(fastRetries()))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	resp, err := c.Do(context.Background(), &httpx.Request{Method: http.MethodPost, Path: "add_json", Body: strings.NewReader(`{"a":1}`)})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+	if len(seen) != 2 || seen[0] != "Bearer token-1" || seen[1] != "Bearer token-2" {
+		t.Fatalf("unexpected Authorization headers %v", seen)
+	}
+}
+
+func TestCachedTokenRefreshesWithinLeeway(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name    string
+		ttl     time.Duration // zero means no expiry
+		fetches int
+	}{
+		{name: "no expiry", ttl: 0, fetches: 1},
+		{name: "valid", ttl: time.Hour, fetches: 1},
+		{name: "inside leeway", ttl: 10 * time.Second, fetches: 3},
+		{name: "expired", ttl: -time.Minute, fetches: 3},
+	}
+	for _, tc := range cases {
+		var fetches int
+		src := httpx.CachedToken(func(context.Context) (string, time.Time, error) {
+			fetches++
+			var expiry time.Time
+			if tc.ttl != 0 {
+				expiry = time.Now().Add(tc.ttl)
+			}
+			return fmt.Sprintf("token-%d", fetches), expiry, nil
+		})
+		for i := 0; i < 3; i++ {
+			token, err := src.Token(ctx)
+			if err != nil {
+				t.Fatalf("%s: Token: %v", tc.name, err)
+			}
+			if token != fmt.Sprintf("token-%d", fetches) {
+				t.Fatalf("%s: got %q after %d fetches", tc.name, token, fetches)
+			}
+		}
+		if fetches != tc.fetches {
+			t.Fatalf("%s: expected %d fetches, got %d", tc.name, tc.fetches, fetches)
+		}
+	}
+}
+
+func TestCachedTokenDoesNotCacheErrors(t *testing.T) {
+	var fetches int
+	src := httpx.CachedToken(func(context.Context) (string, time.Time, error) {
+		fetches++
+		if fetches == 1 {
+			return "", time.Time{}, fmt.Errorf("issuer unavailable")
+		}
+		return "token", time.Now().Add(time.Hour), nil
+	})
+	if _, err := src.Token(context.Background()); err == nil {
+		t.Fatalf("expected the fetch error")
+	}
+	if token, err := src.Token(context.Background()); err != nil || token != "token" {
+		t.Fatalf("Token after failure: %q err=%v", token, err)
+	}
+}
@@ -0,0 +1,252 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the server while the circuit
+// for a route is open. When the circuit opens while a request is retrying, the
+// returned error wraps both ErrCircuitOpen and the last HTTPError.
+var ErrCircuitOpen = errors.New("httpx: circuit breaker is open")
+
+// CircuitState is the state of the circuit guarding a route.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through and counts consecutive failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects requests with ErrCircuitOpen until OpenTimeout
+	// has passed.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests through; the
+	// first result closes or reopens the circuit.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// BreakerPolicy configures the circuit breaker installed by WithCircuitBreaker.
+// Each request path ("/get", "/add_json", ...) has its own circuit. Transport
+// errors and 5xx responses count as failures; any other response counts as a
+// success. Every attempt is recorded, so a circuit that opens mid-request
+// stops the remaining retries.
+type BreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failures that opens a
+	// circuit. Defaults to 5.
+	FailureThreshold int
+	// RouteThresholds overrides FailureThreshold for individual paths.
+	RouteThresholds map[string]int
+	// OpenTimeout is how long a circuit stays open before allowing probes.
+	// Defaults to 30s.
+	OpenTimeout time.Duration
+	// HalfOpenProbes bounds the concurrent requests allowed while half-open.
+	// Defaults to 1.
+	HalfOpenProbes int
+	// OnStateChange, when set, is called after a route's circuit changes
+	// state. It runs synchronously on the goroutine that caused the change,
+	// after the breaker lock is released, so concurrent requests may deliver
+	// calls for the same route concurrently or out of order. Rely on from and
+	// to rather than on arrival order, and use Client.CircuitState for the
+	// current state.
+	OnStateChange func(route string, from, to CircuitState)
+}
+
+// DefaultBreakerPolicy is applied for zero-valued BreakerPolicy fields.
+var DefaultBreakerPolicy = BreakerPolicy{
+	FailureThreshold: 5,
+	OpenTimeout:      30 * time.Second,
+	HalfOpenProbes:   1,
+}
+
+// WithCircuitBreaker enables a per-route circuit breaker.
+func WithCircuitBreaker(policy BreakerPolicy) Option {
+	return func(c *Client) {
+		c.breaker = newBreaker(policy)
+	}
+}
+
+// CircuitState reports the current state of the circuit for path. It is
+// CircuitClosed when no breaker is configured.
+func (c *Client) CircuitState(path string) CircuitState {
+	if c == nil || c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.state(routeKey(path))
+}
+
+type breaker struct {
+	policy BreakerPolicy
+	now    func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probes   int // in-flight requests while half-open
+}
+
+func newBreaker(policy BreakerPolicy) *breaker {
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = DefaultBreakerPolicy.FailureThreshold
+	}
+	if policy.OpenTimeout <= 0 {
+		policy.OpenTimeout = DefaultBreakerPolicy.OpenTimeout
+	}
+	if policy.HalfOpenProbes <= 0 {
+		policy.HalfOpenProbes = DefaultBreakerPolicy.HalfOpenProbes
+	}
+	thresholds := make(map[string]int, len(policy.RouteThresholds))
+	for route, n := range policy.RouteThresholds {
+		thresholds[routeKey(route)] = n
+	}
+	policy.RouteThresholds = thresholds
+	return &breaker{policy: policy, now: time.Now, circuits: make(map[string]*circuit)}
+}
+
+func routeKey(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return "/" + path
+	}
+	return path
+}
+
+func (b *breaker) threshold(route string) int {
+	if n, ok := b.policy.RouteThresholds[route]; ok && n > 0 {
+		return n
+	}
+	return b.policy.FailureThreshold
+}
+
+func (b *breaker) circuit(route string) *circuit {
+	cb, ok := b.circuits[route]
+	if !ok {
+		cb = &circuit{}
+		b.circuits[route] = cb
+	}
+	return cb
+}
+
+// state reports the state of route, moving an expired open circuit to
+// half-open.
+func (b *breaker) state(route string) CircuitState {
+	b.mu.Lock()
+	cb := b.circuit(route)
+	from := cb.state
+	b.expire(cb)
+	to := cb.state
+	b.mu.Unlock()
+	b.notify(route, from, to)
+	return to
+}
+
+// allow reports whether a request to route may be sent. While half-open it
+// reserves a probe slot, reported by probe, that record releases.
+func (b *breaker) allow(route string) (probe, ok bool) {
+	b.mu.Lock()
+	cb := b.circuit(route)
+	from := cb.state
+	b.expire(cb)
+	ok = true
+	switch cb.state {
+	case CircuitOpen:
+		ok = false
+	case CircuitHalfOpen:
+		if cb.probes >= b.policy.HalfOpenProbes {
+			ok = false
+		} else {
+			cb.probes++
+			probe = true
+		}
+	}
+	to := cb.state
+	b.mu.Unlock()
+	b.notify(route, from, to)
+	return probe, ok
+}
+
+// record reports the outcome of an attempt admitted by allow. Outcomes that
+// say nothing about the server, such as a cancelled context, only release
+// the probe slot.
+func (b *breaker) record(route string, probe bool, resp *http.Response, err error) {
+	b.mu.Lock()
+	cb := b.circuit(route)
+	from := cb.state
+	if probe && cb.state == CircuitHalfOpen && cb.probes > 0 {
+		cb.probes--
+	}
+	switch {
+	case err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+	case err != nil || resp == nil || resp.StatusCode >= 500:
+		cb.failures++
+		if cb.state == CircuitHalfOpen || cb.failures >= b.threshold(route) {
+			cb.state = CircuitOpen
+			cb.openedAt = b.now()
+			cb.probes = 0
+		}
+	default:
+		cb.failures = 0
+		if cb.state == CircuitHalfOpen {
+			cb.state = CircuitClosed
+			cb.probes = 0
+		}
+	}
+	to := cb.state
+	b.mu.Unlock()
+	b.notify(route, from, to)
+}
+
+// release returns the probe slot reserved by allow for an attempt that was
+// never sent.
+func (b *breaker) release(route string, probe bool) {
+	if !probe {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if cb := b.circuit(route); cb.state == CircuitHalfOpen && cb.probes > 0 {
+		cb.probes--
+	}
+}
+
+func (b *breaker) expire(cb *circuit) {
+	if cb.state == CircuitOpen && b.now().Sub(cb.openedAt) >= b.policy.OpenTimeout {
+		cb.state = CircuitHalfOpen
+		cb.failures = 0
+		cb.probes = 0
+	}
+}
+
+// circuitOpenError reports that the circuit for route is open, wrapping the
+// failure of the attempt that opened it when there is one.
+func circuitOpenError(route string, cause error) error {
+	if cause == nil {
+		return fmt.Errorf("%w: %s", ErrCircuitOpen, route)
+	}
+	return fmt.Errorf("%w: %s: %w", ErrCircuitOpen, route, cause)
+}
+
+func (b *breaker) notify(route string, from, to CircuitState) {
+	if from != to && b.policy.OnStateChange != nil {
+		b.policy.OnStateChange(route, from, to)
+	}
+}
@@ -0,0 +1,132 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ratio1/edge_sdk_go/internal/httpx"
+)
+
+func TestCircuitBreakerFailsFast(t *testing.T) {
+	var (
+		down atomic.Bool
+		hits atomic.Int32
+	)
+	down.Store(true)
+	url := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var (
+		mu          sync.Mutex
+		transitions []string
+	)
+	c, err := httpx.NewClient(url,
+		httpx.WithRetryPolicy(httpx.RetryPolicy{MaxRetries: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		httpx.WithCircuitBreaker(httpx.BreakerPolicy{
+			FailureThreshold: 3,
+			RouteThresholds:  map[string]int{"set": 1},
+			OpenTimeout:      100 * time.Millisecond,
+			OnStateChange: func(route string, from, to httpx.CircuitState) {
+				mu.Lock()
+				defer mu.Unlock()
+				transitions = append(transitions, route+" "+from.String()+"->"+to.String())
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	ctx := context.Background()
+	get := &httpx.Request{Method: http.MethodGet, Path: "get"}
+	set := &httpx.Request{Method: http.MethodPost, Path: "set", Idempotency: httpx.Idempotent}
+
+	// The third consecutive failure opens the circuit and ends the retries.
+	_, err = c.Do(ctx, get)
+	var httpErr *httpx.HTTPError
+	if !errors.Is(err, httpx.ErrCircuitOpen) || !errors.As(err, &httpErr) || httpErr.Attempts != 3 || hits.Load() != 3 {
+		t.Fatalf("expected 3 attempts before the circuit opened, hits=%d err=%v", hits.Load(), err)
+	}
+	if c.CircuitState("get") != httpx.CircuitOpen {
+		t.Fatalf("expected /get to be open, got %v", c.CircuitState("get"))
+	}
+	if _, err := c.Do(ctx, get); !errors.Is(err, httpx.ErrCircuitOpen) || errors.As(err, &httpErr) {
+		t.Fatalf("expected a bare ErrCircuitOpen, got %v", err)
+	}
+	if hits.Load() != 3 {
+		t.Fatalf("open circuit must not reach the server, hits=%d", hits.Load())
+	}
+
+	// /set has its own circuit with a lower threshold.
+	_, err = c.Do(ctx, set)
+	if !errors.Is(err, httpx.ErrCircuitOpen) || !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusInternalServerError || httpErr.Attempts != 1 {
+		t.Fatalf("expected the first set to reach the server and open the circuit, got %v", err)
+	}
+	if _, err := c.Do(ctx, set); !errors.Is(err, httpx.ErrCircuitOpen) || hits.Load() != 4 {
+		t.Fatalf("expected ErrCircuitOpen for set, hits=%d err=%v", hits.Load(), err)
+	}
+
+	down.Store(false)
+	time.Sleep(150 * time.Millisecond)
+	resp, err := c.Do(ctx, get)
+	if err != nil {
+		t.Fatalf("Do after recovery: %v", err)
+	}
+	resp.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{
+		"/get closed->open",
+		"/set closed->open",
+		"/get open->half-open",
+		"/get half-open->closed",
+	}
+	if !reflect.DeepEqual(transitions, want) {
+		t.Fatalf("unexpected transitions:\n got %q\nwant %q", transitions, want)
+	}
+}
+
+func TestOpenCircuitSkipsAuthentication(t *testing.T) {
+	var hits atomic.Int32
+	url := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	var authCalls atomic.Int32
+	auth := httpx.AuthenticatorFunc(func(req *http.Request) error {
+		authCalls.Add(1)
+		return nil
+	})
+	c, err := httpx.NewClient(url,
+		httpx.WithAuthenticator(auth),
+		httpx.WithRetryPolicy(httpx.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		httpx.WithCircuitBreaker(httpx.BreakerPolicy{FailureThreshold: 1, OpenTimeout: time.Minute}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	req := &httpx.Request{Method: http.MethodGet, Path: "get"}
+	if _, err := c.Do(context.Background(), req); !errors.Is(err, httpx.ErrCircuitOpen) {
+		t.Fatalf("expected the first failure to open the circuit, got %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := c.Do(context.Background(), req); !errors.Is(err, httpx.ErrCircuitOpen) {
+			t.Fatalf("expected ErrCircuitOpen, got %v", err)
+		}
+	}
+	if hits.Load() != 1 || authCalls.Load() != 1 {
+		t.Fatalf("open circuit reached the authenticator %d times and the server %d times", authCalls.Load(), hits.Load())
+	}
+}
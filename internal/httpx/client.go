@@ -3,25 +3,46 @@ package httpx
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // RetryPolicy controls the retry behaviour for transient failures.
+//
+// Idempotent requests are retried on transport errors and on 408, 429 and 5xx
+// responses. Other requests are only retried when the server cannot have
+// acted on them: failed connection attempts and 408, 429 and 503 responses.
+// A Retry-After header on the response replaces the backoff delay; when it
+// asks for more than MaxElapsed allows, or more than MaxRetryAfter with no
+// MaxElapsed set, the error is returned instead of waiting.
 type RetryPolicy struct {
 	MaxRetries int
 	BaseDelay  time.Duration
 	MaxDelay   time.Duration
 	Jitter     float64
-	RetryIf    func(resp *http.Response, err error) bool
+	// MaxElapsed bounds the total time spent on a request including retries
+	// and waits; a retry that would exceed it is not attempted. Zero means no
+	// limit beyond MaxRetries and the context deadline.
+	MaxElapsed time.Duration
+	// RetryIf replaces the default classification when set. It receives
+	// the response and its *HTTPError for error statuses, or a nil response
+	// and the transport error.
+	RetryIf func(resp *http.Response, err error) bool
 }
 
+// MaxRetryAfter is the longest Retry-After delay honoured when
+// RetryPolicy.MaxElapsed is zero.
+const MaxRetryAfter = 30 * time.Second
+
 // DefaultRetryPolicy implements a conservative retry strategy.
 var DefaultRetryPolicy = RetryPolicy{
 	MaxRetries: 3,
@@ -66,8 +87,27 @@ type Client struct {
 	httpClient  *http.Client
 	headers     http.Header
 	retryPolicy RetryPolicy
+	auth        Authenticator
+	breaker     *breaker
+	tlsConfig   *tls.Config
+	socketPath  string // set for unix:// base URLs
+	optErr      error  // first error reported by an Option
 }
 
+// Idempotency tells the retry logic whether repeating a request is safe.
+type Idempotency int
+
+const (
+	// IdempotencyDefault derives idempotency from the HTTP method: GET, HEAD,
+	// OPTIONS, PUT and DELETE are idempotent.
+	IdempotencyDefault Idempotency = iota
+	// Idempotent marks a request that may be repeated, such as a POST that
+	// only reads.
+	Idempotent
+	// NonIdempotent marks a request that must not be applied twice.
+	NonIdempotent
+)
+
 // Request describes a single outbound request.
 type Request struct {
 	Method       string
@@ -75,6 +115,7 @@ type Request struct {
 	Query        url.Values
 	Header       http.Header
 	DisableRetry bool
+	Idempotency  Idempotency
 	Body         io.Reader
 	GetBody      func() (io.ReadCloser, error)
 }
@@ -89,6 +130,16 @@ func NewClient(baseURL string, opts ...Option) (*Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("httpx: invalid base URL: %w", err)
 	}
+	var socketPath string
+	if parsed.Scheme == "unix" {
+		// unix:///path/to.sock talks HTTP over the socket; requests are
+		// addressed to a placeholder host rooted at "/".
+		socketPath = parsed.Path
+		if parsed.Host != "" || socketPath == "" {
+			return nil, fmt.Errorf("httpx: invalid unix socket URL %q, want unix:///path/to.sock", baseURL)
+		}
+		parsed = &url.URL{Scheme: "http", Host: "unix", Path: "/"}
+	}
 
 	c := &Client{
 		baseURL: parsed,
@@ -97,11 +148,18 @@ func NewClient(baseURL string, opts ...Option) (*Client, error) {
 		},
 		headers:     make(http.Header),
 		retryPolicy: DefaultRetryPolicy,
+		socketPath:  socketPath,
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
+	if c.optErr != nil {
+		return nil, c.optErr
+	}
+	if err := c.configureTransport(); err != nil {
+		return nil, err
+	}
 
 	if c.retryPolicy.MaxRetries < 0 {
 		c.retryPolicy.MaxRetries = 0
@@ -116,6 +174,9 @@ func NewClient(baseURL string, opts ...Option) (*Client, error) {
 }
 
 // Do executes the provided request and returns the response, or an HTTPError.
+// Requests to a route whose circuit is open fail with an error wrapping
+// ErrCircuitOpen, which also wraps the last HTTPError when the circuit opened
+// during this request.
 func (c *Client) Do(ctx context.Context, req *Request) (*http.Response, error) {
 	if req == nil {
 		return nil, errors.New("httpx: request is nil")
@@ -129,21 +190,20 @@ func (c *Client) Do(ctx context.Context, req *Request) (*http.Response, error) {
 		return nil, errors.New("httpx: HTTP method is required")
 	}
 
-	if req.DisableRetry {
-		req.GetBody = nil
-	} else if req.GetBody == nil && req.Body == nil {
-		// no body is OK
-	} else if req.GetBody == nil && req.Body != nil {
-		// Attempt to buffer the body for retries.
+	// Bodies that are already in memory, or that must be buffered for
+	// retries, are replayed from a byte slice. net/http then knows their
+	// length and authenticators can hash them through http.Request.GetBody.
+	var payload []byte
+	buffered := false
+	switch {
+	case req.Body == nil && req.GetBody == nil:
+		buffered = true
+	case isInMemory(req.Body) || (req.Body != nil && req.GetBody == nil && !req.DisableRetry):
 		data, err := io.ReadAll(req.Body)
 		if err != nil {
 			return nil, fmt.Errorf("httpx: read request body: %w", err)
 		}
-		reader := bytes.NewReader(data)
-		req.Body = reader
-		req.GetBody = func() (io.ReadCloser, error) {
-			return io.NopCloser(bytes.NewReader(data)), nil
-		}
+		payload, buffered = data, true
 	}
 
 	fullURL, err := c.buildURL(req.Path, req.Query)
@@ -151,7 +211,10 @@ func (c *Client) Do(ctx context.Context, req *Request) (*http.Response, error) {
 		return nil, err
 	}
 
+	route := routeKey(req.Path)
+	start := time.Now()
 	attempt := 0
+	var lastFailure error
 	backoff := NewBackoff(c.retryPolicy.BaseDelay, c.retryPolicy.MaxDelay, c.retryPolicy.Jitter)
 	for {
 		select {
@@ -160,69 +223,137 @@ func (c *Client) Do(ctx context.Context, req *Request) (*http.Response, error) {
 		default:
 		}
 
-		body, err := c.prepareBody(req, attempt == 0)
-		if err != nil {
-			return nil, err
+		// The breaker is consulted before the body is prepared and the
+		// request authenticated, so an open circuit costs no token fetch or
+		// body hashing.
+		var probe bool
+		if c.breaker != nil {
+			var ok bool
+			if probe, ok = c.breaker.allow(route); !ok {
+				if lastFailure != nil {
+					return nil, circuitOpenError(route, finalError(lastFailure, attempt))
+				}
+				return nil, circuitOpenError(route, nil)
+			}
 		}
 
-		httpReq, err := http.NewRequestWithContext(ctx, req.Method, fullURL, body)
+		httpReq, err := c.newHTTPRequest(ctx, req, fullURL, attempt, buffered, payload)
 		if err != nil {
-			return nil, err
-		}
-
-		httpReq.Header = cloneHeader(c.headers)
-		for k, values := range req.Header {
-			for _, v := range values {
-				httpReq.Header.Add(k, v)
+			if c.breaker != nil {
+				c.breaker.release(route, probe)
 			}
+			return nil, err
 		}
 
 		resp, err := c.httpClient.Do(httpReq)
-		if err != nil {
+		if c.breaker != nil {
+			c.breaker.record(route, probe, resp, err)
+		}
+		var failure error
+		switch {
+		case err != nil:
 			closeBody(respBody(resp))
-			if !c.shouldRetry(req, attempt, resp, err) {
-				return nil, err
-			}
-			delay := backoff.ForAttempt(attempt)
-			attempt++
-			if err := c.sleep(ctx, delay); err != nil {
-				return nil, err
-			}
-			continue
+			resp, failure = nil, err
+		case resp.StatusCode >= 400:
+			failure = c.handleError(resp)
+		default:
+			return resp, nil
 		}
 
-		if resp.StatusCode >= 400 {
-			err = c.handleError(resp)
-			if !c.shouldRetry(req, attempt, resp, err) {
-				return nil, err
-			}
-			delay := backoff.ForAttempt(attempt)
-			attempt++
-			if err := c.sleep(ctx, delay); err != nil {
-				return nil, err
-			}
-			continue
+		delay, retry := c.retryDelay(ctx, req, attempt, start, resp, failure, &backoff)
+		// A circuit opened by this attempt ends the request without waiting
+		// out the backoff.
+		if c.breaker != nil && c.breaker.state(route) == CircuitOpen {
+			return nil, circuitOpenError(route, finalError(failure, attempt+1))
+		}
+		if !retry {
+			return nil, finalError(failure, attempt+1)
+		}
+		lastFailure = failure
+		attempt++
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
 		}
-
-		return resp, nil
 	}
 }
 
-func (c *Client) prepareBody(req *Request, first bool) (io.ReadCloser, error) {
-	if first && req.Body != nil {
-		body := req.Body
-		req.Body = nil
-		if rc, ok := body.(io.ReadCloser); ok {
-			return rc, nil
+// newHTTPRequest builds and authenticates the request for one attempt.
+func (c *Client) newHTTPRequest(ctx context.Context, req *Request, fullURL string, attempt int, buffered bool, payload []byte) (*http.Request, error) {
+	body, err := c.prepareBody(req, attempt, buffered, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, fullURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header = cloneHeader(c.headers)
+	for k, values := range req.Header {
+		for _, v := range values {
+			httpReq.Header.Add(k, v)
+		}
+	}
+
+	if c.auth != nil {
+		if err := c.auth.Authenticate(httpReq); err != nil {
+			closeBody(httpReq.Body)
+			return nil, fmt.Errorf("httpx: authenticate request: %w", err)
 		}
-		return io.NopCloser(body), nil
 	}
-	if req.GetBody != nil {
+	return httpReq, nil
+}
+
+func (c *Client) prepareBody(req *Request, attempt int, buffered bool, payload []byte) (io.Reader, error) {
+	switch {
+	case buffered:
+		return bytes.NewReader(payload), nil
+	case attempt == 0 && req.Body != nil:
+		return req.Body, nil
+	case req.GetBody != nil:
 		return req.GetBody()
 	}
 	return http.NoBody, nil
 }
 
+// isInMemory reports whether r is one of the readers net/http knows how to
+// replay on its own.
+func isInMemory(r io.Reader) bool {
+	switch r.(type) {
+	case *bytes.Reader, *bytes.Buffer, *strings.Reader:
+		return true
+	}
+	return false
+}
+
+// retryDelay reports whether a failed attempt should be retried and how long
+// to wait first. resp is nil for transport failures; err is the failure
+// returned to the caller, an *HTTPError for error statuses.
+func (c *Client) retryDelay(ctx context.Context, req *Request, attempt int, start time.Time, resp *http.Response, err error, backoff *Backoff) (time.Duration, bool) {
+	if !c.shouldRetry(req, attempt, resp, err) {
+		return 0, false
+	}
+	delay := backoff.ForAttempt(attempt)
+	if resp != nil {
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			// The server asked us to wait; give up rather than retry early
+			// when that is longer than we are prepared to wait.
+			if c.retryPolicy.MaxElapsed == 0 && after > MaxRetryAfter {
+				return 0, false
+			}
+			delay = after
+		}
+	}
+	if budget := c.retryPolicy.MaxElapsed; budget > 0 && time.Since(start)+delay > budget {
+		return 0, false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		return 0, false
+	}
+	return delay, true
+}
+
 func (c *Client) shouldRetry(req *Request, attempt int, resp *http.Response, err error) bool {
 	if req.DisableRetry {
 		return false
@@ -233,26 +364,75 @@ func (c *Client) shouldRetry(req *Request, attempt int, resp *http.Response, err
 	if c.retryPolicy.RetryIf != nil {
 		return c.retryPolicy.RetryIf(resp, err)
 	}
-	if err != nil {
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+	idempotent := isIdempotent(req)
+	if resp == nil {
+		if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			return false
 		}
-		return true
+		return idempotent || isDialError(err)
 	}
-	if resp == nil {
-		return false
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusRequestTimeout, http.StatusServiceUnavailable:
+		return true
 	}
-	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout {
-		closeBody(resp.Body)
+	return idempotent && resp.StatusCode >= 500 && resp.StatusCode <= 599
+}
+
+func isIdempotent(req *Request) bool {
+	switch req.Idempotency {
+	case Idempotent:
 		return true
+	case NonIdempotent:
+		return false
 	}
-	if resp.StatusCode >= 500 && resp.StatusCode <= 599 {
-		closeBody(resp.Body)
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
 		return true
 	}
 	return false
 }
 
+// isDialError reports whether err happened while connecting, before any part
+// of the request reached the server.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// parseRetryAfter reads a Retry-After value given in seconds or as an HTTP
+// date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := at.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// finalError records the number of attempts on the error returned by Do.
+// Transport failures are wrapped in an HTTPError whose Cause holds them.
+func finalError(err error, attempts int) error {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		httpErr.Attempts = attempts
+		return err
+	}
+	return &HTTPError{Attempts: attempts, Cause: err}
+}
+
 func (c *Client) sleep(ctx context.Context, d time.Duration) error {
 	if d <= 0 {
 		return nil
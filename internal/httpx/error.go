@@ -6,19 +6,40 @@ import (
 	"net/http"
 )
 
-// HTTPError represents a non-2xx HTTP response returned by the remote service.
+// HTTPError represents a non-2xx HTTP response returned by the remote service,
+// or a request that failed in transport, in which case StatusCode is zero and
+// Cause holds the transport error.
 type HTTPError struct {
 	StatusCode int
 	Body       []byte
 	Header     http.Header
 	JSON       any
+	// Attempts is the number of attempts made, including retries.
+	Attempts int
+	// Cause is the transport error that ended the final attempt.
+	Cause error
 }
 
 func (e *HTTPError) Error() string {
 	if e == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("http error: status=%d body=%s", e.StatusCode, string(e.Body))
+	if e.StatusCode == 0 && e.Cause != nil {
+		return fmt.Sprintf("http error: attempts=%d: %v", e.Attempts, e.Cause)
+	}
+	msg := fmt.Sprintf("http error: status=%d body=%s", e.StatusCode, string(e.Body))
+	if e.Attempts > 1 {
+		msg += fmt.Sprintf(" attempts=%d", e.Attempts)
+	}
+	return msg
+}
+
+// Unwrap returns Cause.
+func (e *HTTPError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Cause
 }
 
 // Retryable reports whether the error should be considered transient.
@@ -26,6 +47,9 @@ func (e *HTTPError) Retryable() bool {
 	if e == nil {
 		return false
 	}
+	if e.StatusCode == 0 {
+		return e.Cause != nil
+	}
 	return e.StatusCode == http.StatusTooManyRequests ||
 		e.StatusCode == http.StatusRequestTimeout ||
 		(e.StatusCode >= 500 && e.StatusCode <= 599)
@@ -0,0 +1,162 @@
+package httpx_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ratio1/edge_sdk_go/internal/httpx"
+)
+
+func serve(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("network disabled for tests: %v", err)
+	}
+	srv := &http.Server{Handler: handler}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return "http://" + ln.Addr().String()
+}
+
+func newClient(t *testing.T, baseURL string, policy httpx.RetryPolicy) *httpx.Client {
+	t.Helper()
+	c, err := httpx.NewClient(baseURL, httpx.WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return c
+}
+
+func TestRetryAfterReplacesBackoff(t *testing.T) {
+	var calls atomic.Int32
+	url := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	// Without Retry-After the first backoff would take a minute.
+	c := newClient(t, url, httpx.RetryPolicy{MaxRetries: 2, BaseDelay: time.Minute, MaxDelay: time.Minute})
+	start := time.Now()
+	resp, err := c.Do(context.Background(), &httpx.Request{Method: http.MethodPost, Path: "set"})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+	if calls.Load() != 2 || time.Since(start) > 10*time.Second {
+		t.Fatalf("expected an immediate retry, calls=%d elapsed=%s", calls.Load(), time.Since(start))
+	}
+}
+
+func TestRetryBudgetStopsLongRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	url := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", time.Now().Add(2*time.Minute).UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	c := newClient(t, url, httpx.RetryPolicy{MaxRetries: 3, MaxElapsed: time.Second})
+	_, err := c.Do(context.Background(), &httpx.Request{Method: http.MethodGet, Path: "get"})
+	var httpErr *httpx.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests || httpErr.Attempts != 1 {
+		t.Fatalf("expected a single 429 attempt, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected no retry beyond the budget, calls=%d", calls.Load())
+	}
+}
+
+func TestLongRetryAfterWithoutBudgetGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	url := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	c := newClient(t, url, httpx.RetryPolicy{MaxRetries: 3})
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Do(context.Background(), &httpx.Request{Method: http.MethodGet, Path: "get"})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		var httpErr *httpx.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+			t.Fatalf("expected the 503 without retrying, calls=%d err=%v", calls.Load(), err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Do waited for Retry-After beyond MaxRetryAfter")
+	}
+}
+
+func TestRetryIfReceivesHTTPError(t *testing.T) {
+	url := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	var seen []error
+	c := newClient(t, url, httpx.RetryPolicy{
+		MaxRetries: 1,
+		BaseDelay:  time.Millisecond,
+		RetryIf: func(resp *http.Response, err error) bool {
+			seen = append(seen, err)
+			return true
+		},
+	})
+	if _, err := c.Do(context.Background(), &httpx.Request{Method: http.MethodPost, Path: "set"}); err == nil {
+		t.Fatalf("expected an error")
+	}
+	var httpErr *httpx.HTTPError
+	if len(seen) != 1 || !errors.As(seen[0], &httpErr) || httpErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("RetryIf saw %v", seen)
+	}
+}
+
+func TestNonIdempotentRequestsAreNotReplayed(t *testing.T) {
+	var calls atomic.Int32
+	url := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	c := newClient(t, url, httpx.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	_, err := c.Do(context.Background(), &httpx.Request{Method: http.MethodPost, Path: "set"})
+	var httpErr *httpx.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Attempts != 1 || calls.Load() != 1 {
+		t.Fatalf("POST should not be retried after a 500: calls=%d err=%v", calls.Load(), err)
+	}
+
+	calls.Store(0)
+	_, err = c.Do(context.Background(), &httpx.Request{Method: http.MethodPost, Path: "get_file_base64", Idempotency: httpx.Idempotent})
+	if !errors.As(err, &httpErr) || httpErr.Attempts != 3 || calls.Load() != 3 {
+		t.Fatalf("idempotent POST should be retried: calls=%d err=%v", calls.Load(), err)
+	}
+}
+
+func TestDialErrorsAreRetriedAndExposeCause(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("network disabled for tests: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	c := newClient(t, "http://"+addr, httpx.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	_, err = c.Do(context.Background(), &httpx.Request{Method: http.MethodPost, Path: "set"})
+	var httpErr *httpx.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != 0 || httpErr.Attempts != 3 {
+		t.Fatalf("expected 3 attempts for a refused connection, got %v", err)
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Op != "dial" {
+		t.Fatalf("expected the dial error as cause, got %#v", httpErr.Cause)
+	}
+}
@@ -0,0 +1,122 @@
+package httpx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables read by TLSConfigFromEnv.
+const (
+	EnvTLSCert       = "EE_R1_TLS_CERT"        // client certificate (PEM file)
+	EnvTLSKey        = "EE_R1_TLS_KEY"         // client private key (PEM file)
+	EnvTLSCA         = "EE_R1_TLS_CA"          // CA bundle (PEM file)
+	EnvTLSServerName = "EE_R1_TLS_SERVER_NAME" // server name override
+	EnvTLSMinVersion = "EE_R1_TLS_MIN_VERSION" // "1.2" or "1.3"
+)
+
+// TLSConfig describes the TLS settings applied to the client transport. File
+// fields hold PEM encoded material.
+type TLSConfig struct {
+	// CertFile and KeyFile hold the client certificate presented for mutual
+	// TLS. Both must be set together.
+	CertFile string
+	KeyFile  string
+	// CAFile holds the CA bundle used to verify the server instead of the
+	// system roots.
+	CAFile string
+	// ServerName overrides the name checked against the server certificate.
+	ServerName string
+	// MinVersion is the minimum TLS version, such as tls.VersionTLS12. Zero
+	// keeps the crypto/tls default.
+	MinVersion uint16
+}
+
+// Build loads the referenced files into a tls.Config.
+func (c TLSConfig) Build() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName: c.ServerName,
+		MinVersion: c.MinVersion,
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, errors.New("httpx: TLS client certificate and key must be set together")
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("httpx: load TLS client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("httpx: read TLS CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("httpx: TLS CA bundle %s contains no certificates", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// ParseTLSVersion converts "1.0" to "1.3" (optionally prefixed with "TLS")
+// into a crypto/tls version constant.
+func ParseTLSVersion(s string) (uint16, error) {
+	v := strings.TrimSpace(strings.ToUpper(s))
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "TLS"), "V")
+	switch strings.TrimSpace(v) {
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("httpx: unknown TLS version %q", s)
+}
+
+// TLSConfigFromEnv reads the EE_R1_TLS_* variables. It returns nil when none
+// of them is set. Files are only loaded by Build.
+func TLSConfigFromEnv() (*TLSConfig, error) {
+	cfg := &TLSConfig{
+		CertFile:   strings.TrimSpace(os.Getenv(EnvTLSCert)),
+		KeyFile:    strings.TrimSpace(os.Getenv(EnvTLSKey)),
+		CAFile:     strings.TrimSpace(os.Getenv(EnvTLSCA)),
+		ServerName: strings.TrimSpace(os.Getenv(EnvTLSServerName)),
+	}
+	if minVersion := strings.TrimSpace(os.Getenv(EnvTLSMinVersion)); minVersion != "" {
+		v, err := ParseTLSVersion(minVersion)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvTLSMinVersion, err)
+		}
+		cfg.MinVersion = v
+	}
+	if *cfg == (TLSConfig{}) {
+		return nil, nil
+	}
+	return cfg, nil
+}
+
+// WithTLS applies cfg to the transport of the HTTP client. It composes with
+// WithHTTPClient as long as that client uses an *http.Transport (or none).
+// Errors loading the files are reported by NewClient.
+func WithTLS(cfg TLSConfig) Option {
+	return func(c *Client) {
+		tlsCfg, err := cfg.Build()
+		if err != nil {
+			if c.optErr == nil {
+				c.optErr = err
+			}
+			return
+		}
+		c.tlsConfig = tlsCfg
+	}
+}
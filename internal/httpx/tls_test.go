@@ -0,0 +1,185 @@
+package httpx_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ratio1/edge_sdk_go/internal/httpx"
+)
+
+// writeTestPair writes a self-signed certificate and its key to dir and
+// returns their paths.
+func writeTestPair(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "r1-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	writeFile(t, certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	writeFile(t, keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+	return certFile, keyFile
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestTLSConfigBuild(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestPair(t, dir)
+	badPEM := filepath.Join(dir, "bad.pem")
+	writeFile(t, badPEM, []byte("not a certificate"))
+
+	cases := []struct {
+		name    string
+		cfg     httpx.TLSConfig
+		wantErr string
+		check   func(*tls.Config) bool
+	}{
+		{name: "empty", check: func(c *tls.Config) bool { return c.RootCAs == nil && len(c.Certificates) == 0 }},
+		{
+			name:  "server name and version",
+			cfg:   httpx.TLSConfig{ServerName: "node.r1", MinVersion: tls.VersionTLS13},
+			check: func(c *tls.Config) bool { return c.ServerName == "node.r1" && c.MinVersion == tls.VersionTLS13 },
+		},
+		{
+			name:  "client certificate and CA",
+			cfg:   httpx.TLSConfig{CertFile: certFile, KeyFile: keyFile, CAFile: certFile},
+			check: func(c *tls.Config) bool { return len(c.Certificates) == 1 && c.RootCAs != nil },
+		},
+		{name: "missing key", cfg: httpx.TLSConfig{CertFile: certFile}, wantErr: "set together"},
+		{name: "missing certificate", cfg: httpx.TLSConfig{KeyFile: keyFile}, wantErr: "set together"},
+		{name: "key is not a key", cfg: httpx.TLSConfig{CertFile: certFile, KeyFile: certFile}, wantErr: "client certificate"},
+		{name: "bad CA PEM", cfg: httpx.TLSConfig{CAFile: badPEM}, wantErr: "contains no certificates"},
+		{name: "missing CA file", cfg: httpx.TLSConfig{CAFile: filepath.Join(dir, "absent.pem")}, wantErr: "CA bundle"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.cfg.Build()
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Build error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			if !tc.check(got) {
+				t.Fatalf("unexpected config %+v", got)
+			}
+		})
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	cases := []struct {
+		in   string
+		want uint16
+	}{
+		{"1.0", tls.VersionTLS10},
+		{"1.1", tls.VersionTLS11},
+		{"1.2", tls.VersionTLS12},
+		{" 1.3 ", tls.VersionTLS13},
+		{"TLS1.2", tls.VersionTLS12},
+		{"tlsv1.3", tls.VersionTLS13},
+		{"TLS 1.3", tls.VersionTLS13},
+		{"13", tls.VersionTLS13},
+	}
+	for _, tc := range cases {
+		got, err := httpx.ParseTLSVersion(tc.in)
+		if err != nil || got != tc.want {
+			t.Fatalf("ParseTLSVersion(%q) = %#x, %v; want %#x", tc.in, got, err, tc.want)
+		}
+	}
+	for _, in := range []string{"", "1.4", "SSL3", "TLS"} {
+		if _, err := httpx.ParseTLSVersion(in); err == nil {
+			t.Fatalf("ParseTLSVersion(%q): expected an error", in)
+		}
+	}
+}
+
+func TestTLSConfigFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestPair(t, dir)
+	badPEM := filepath.Join(dir, "bad.pem")
+	writeFile(t, badPEM, []byte("-----BEGIN CERTIFICATE-----\nbm9wZQ==\n-----END CERTIFICATE-----\n"))
+
+	cases := []struct {
+		name string
+		env  map[string]string
+		// wantErr is matched against TLSConfigFromEnv, buildErr against
+		// Build of the returned config.
+		wantErr  string
+		buildErr string
+	}{
+		{name: "unset"},
+		{name: "client certificate", env: map[string]string{httpx.EnvTLSCert: certFile, httpx.EnvTLSKey: keyFile, httpx.EnvTLSMinVersion: "1.2"}},
+		{name: "unknown min version", env: map[string]string{httpx.EnvTLSMinVersion: "1.4"}, wantErr: httpx.EnvTLSMinVersion},
+		{name: "missing key", env: map[string]string{httpx.EnvTLSCert: certFile}, buildErr: "set together"},
+		{name: "bad CA PEM", env: map[string]string{httpx.EnvTLSCA: badPEM}, buildErr: "contains no certificates"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, name := range []string{httpx.EnvTLSCert, httpx.EnvTLSKey, httpx.EnvTLSCA, httpx.EnvTLSServerName, httpx.EnvTLSMinVersion} {
+				t.Setenv(name, tc.env[name])
+			}
+			cfg, err := httpx.TLSConfigFromEnv()
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("TLSConfigFromEnv error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TLSConfigFromEnv: %v", err)
+			}
+			if len(tc.env) == 0 {
+				if cfg != nil {
+					t.Fatalf("expected nil config, got %+v", cfg)
+				}
+				return
+			}
+			_, err = cfg.Build()
+			if tc.buildErr == "" && err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			if tc.buildErr != "" && (err == nil || !strings.Contains(err.Error(), tc.buildErr)) {
+				t.Fatalf("Build error = %v, want %q", err, tc.buildErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// configureTransport installs the TLS settings and unix socket dialer on a
+// copy of the HTTP client and its transport, so clients passed through
+// WithHTTPClient are left untouched.
+func (c *Client) configureTransport() error {
+	if c.tlsConfig == nil && c.socketPath == "" {
+		return nil
+	}
+	var transport *http.Transport
+	switch rt := c.httpClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = rt.Clone()
+	default:
+		return fmt.Errorf("httpx: cannot apply TLS or unix socket settings to transport %T", rt)
+	}
+	if c.tlsConfig != nil {
+		transport.TLSClientConfig = c.tlsConfig
+	}
+	if c.socketPath != "" {
+		socketPath := c.socketPath
+		var dialer net.Dialer
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+	}
+	hc := *c.httpClient
+	hc.Transport = transport
+	c.httpClient = &hc
+	return nil
+}
+
+// IsLocal reports whether the base URL is a unix socket or a loopback host,
+// meaning the server runs on this machine.
+func (c *Client) IsLocal() bool {
+	if c == nil {
+		return false
+	}
+	if c.socketPath != "" {
+		return true
+	}
+	host := c.baseURL.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
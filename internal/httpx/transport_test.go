@@ -0,0 +1,59 @@
+package httpx_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/internal/httpx"
+)
+
+func TestUnixSocketTransport(t *testing.T) {
+	// Socket paths are limited to about 100 bytes, which t.TempDir can exceed.
+	dir, err := os.MkdirTemp("", "httpx")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	sock := filepath.Join(dir, "api.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Method+" "+r.URL.RequestURI())
+	})}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	// A proxy from the environment must not capture socket requests.
+	t.Setenv("HTTP_PROXY", "http://127.0.0.1:1")
+	c, err := httpx.NewClient("unix://"+sock, httpx.WithRetryPolicy(fastRetries()))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if !c.IsLocal() {
+		t.Fatalf("unix socket client should report IsLocal")
+	}
+	resp, err := c.Do(context.Background(), &httpx.Request{Method: http.MethodGet, Path: "get_status"})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	body, err := httpx.ReadAllAndClose(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if string(body) != "GET /get_status" {
+		t.Fatalf("unexpected response %q", body)
+	}
+
+	for _, bad := range []string{"unix://host/api.sock", "unix://"} {
+		if _, err := httpx.NewClient(bad); err == nil {
+			t.Fatalf("NewClient(%q): expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package cstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// maxUpdateAttempts bounds the optimistic retry loop in Update.
+const maxUpdateAttempts = 16
+
+// CompareAndSwapBackend is an optional Backend extension for stores that can
+// replace a value atomically. old is the payload previously returned by Get or
+// HGet (nil when the entry was missing); the swap must only happen when the
+// stored payload still equals old. A nil new deletes the entry.
+type CompareAndSwapBackend interface {
+	CompareAndSwap(ctx context.Context, key string, old, new []byte) (swapped bool, err error)
+	HCompareAndSwap(ctx context.Context, hashKey, field string, old, new []byte) (swapped bool, err error)
+}
+
+// slot abstracts a single key or hash field for conditional writes.
+type slot struct {
+	get func(ctx context.Context) ([]byte, error)
+	set func(ctx context.Context, raw []byte) error
+	del func(ctx context.Context) error
+	cas func(ctx context.Context, old, new []byte) (bool, error)
+}
+
+func keySlot(b Backend, key string) slot {
+	s := slot{
+		get: func(ctx context.Context) ([]byte, error) { return b.Get(ctx, key) },
+		set: func(ctx context.Context, raw []byte) error { return b.Set(ctx, key, raw, nil) },
+		del: func(ctx context.Context) error { return b.Delete(ctx, key) },
+	}
+	if cb, ok := b.(CompareAndSwapBackend); ok {
+		s.cas = func(ctx context.Context, old, new []byte) (bool, error) {
+			return cb.CompareAndSwap(ctx, key, old, new)
+		}
+	}
+	return s
+}
+
+func hashSlot(b Backend, hashKey, field string) slot {
+	s := slot{
+		get: func(ctx context.Context) ([]byte, error) { return b.HGet(ctx, hashKey, field) },
+		set: func(ctx context.Context, raw []byte) error { return b.HSet(ctx, hashKey, field, raw, nil) },
+		del: func(ctx context.Context) error { return b.HDel(ctx, hashKey, field) },
+	}
+	if cb, ok := b.(CompareAndSwapBackend); ok {
+		s.cas = func(ctx context.Context, old, new []byte) (bool, error) {
+			return cb.HCompareAndSwap(ctx, hashKey, field, old, new)
+		}
+	}
+	return s
+}
+
+// versionIs returns a conditionalSet check requiring the stored version to
+// equal expected.
+func versionIs(expected uint64) func(value []byte, meta *envelopeMeta) error {
+	return func(_ []byte, meta *envelopeMeta) error {
+		if got := versionOf(meta); got != expected {
+			return fmt.Errorf("cstore: expected version %d, found %d: %w", expected, got, ErrConflict)
+		}
+		return nil
+	}
+}
+
+// conditionalSet writes raw when check accepts the stored value, which is nil
+// with a nil meta when the entry is missing or expired. The stored version is
+// incremented. The entry expires after ttl when it is positive; otherwise,
+// with keepExpiry set, it keeps the expiry of the value it replaces.
+//
+// Backends implementing CompareAndSwapBackend apply the write atomically
+// against the exact payload check saw. Other backends, including the HTTP
+// backend, write the value and read it back: a conflicting writer whose write
+// lands after the read-back goes unnoticed, so concurrent updates are best
+// effort there.
+func conditionalSet(ctx context.Context, s slot, raw []byte, ttl time.Duration, keepExpiry bool, check func(value []byte, meta *envelopeMeta) error) error {
+	if ttl < 0 {
+		return fmt.Errorf("cstore: TTL must not be negative")
+	}
+	current, err := s.get(ctx)
+	if err != nil {
+		return err
+	}
+	value, meta := unwrapEnvelope(current)
+	if isNull(current) || meta.expired(time.Now()) {
+		value, meta = nil, nil
+	}
+	if err := check(value, meta); err != nil {
+		return err
+	}
+	token, err := newWriteToken()
+	if err != nil {
+		return err
+	}
+	expiry := expiresAt(ttl)
+	if ttl == 0 && keepExpiry && meta != nil {
+		expiry = meta.ExpiresAt
+	}
+	wrapped, err := wrapEnvelope(raw, envelopeMeta{Version: versionOf(meta) + 1, Token: token, ExpiresAt: expiry})
+	if err != nil {
+		return err
+	}
+
+	if s.cas != nil {
+		var old []byte
+		if !isNull(current) {
+			old = current
+		}
+		swapped, err := s.cas(ctx, old, wrapped)
+		if err != nil {
+			return err
+		}
+		if !swapped {
+			return fmt.Errorf("cstore: concurrent write detected: %w", ErrConflict)
+		}
+		return nil
+	}
+
+	if err := s.set(ctx, wrapped); err != nil {
+		return err
+	}
+	after, err := s.get(ctx)
+	if err != nil {
+		return err
+	}
+	if _, afterMeta := unwrapEnvelope(after); afterMeta == nil || afterMeta.Token != token {
+		return fmt.Errorf("cstore: concurrent write detected: %w", ErrConflict)
+	}
+	return nil
+}
+
+// CompareAndSwap replaces the value stored under key with new when the current
+// value is JSON-equal to old. Pass a nil old to require that the key is
+// missing. It reports whether the swap happened; a lost race is reported as
+// (false, nil). The comparison and the swap use the same read, so the swap is
+// atomic on CompareAndSwapBackend stores and best effort over HTTP (see
+// SetOptions.IfVersion). A key written with SetOptions.TTL keeps its expiry.
+func (c *Client) CompareAndSwap(ctx context.Context, key string, old, new any) (swapped bool, err error) {
+	if strings.TrimSpace(key) == "" {
+		return false, fmt.Errorf("cstore: key is required")
+	}
+	if c == nil || c.backend == nil {
+		return false, fmt.Errorf("cstore: client is nil")
+	}
+	var expected []byte
+	if old != nil {
+		if expected, err = marshalJSON(old); err != nil {
+			return false, fmt.Errorf("cstore: encode expected value: %w", err)
+		}
+	}
+	raw, err := marshalJSON(new)
+	if err != nil {
+		return false, fmt.Errorf("cstore: encode value: %w", err)
+	}
+	err = conditionalSet(ctx, keySlot(c.backend, key), raw, 0, true, func(value []byte, _ *envelopeMeta) error {
+		if (expected == nil) != (value == nil) || (expected != nil && !jsonEqual(expected, value)) {
+			return ErrConflict
+		}
+		return nil
+	})
+	if errors.Is(err, ErrConflict) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Update applies fn to the current value of key and stores the result,
+// retrying when another writer changes the key in between. fn receives nil
+// when the key is missing and may be called several times. A key written
+// with SetOptions.TTL keeps its expiry. Like SetOptions.IfVersion, conflicts
+// are only guaranteed to be detected on CompareAndSwapBackend stores.
+func (c *Client) Update(ctx context.Context, key string, fn func(old json.RawMessage) (any, error)) error {
+	if strings.TrimSpace(key) == "" {
+		return fmt.Errorf("cstore: key is required")
+	}
+	if fn == nil {
+		return fmt.Errorf("cstore: update function is required")
+	}
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		item, err := getItem[json.RawMessage](ctx, c, key)
+		if err != nil {
+			return err
+		}
+		var (
+			current json.RawMessage
+			version uint64
+		)
+		if item != nil {
+			current = item.Value
+			version = item.Version
+		}
+		next, err := fn(current)
+		if err != nil {
+			return err
+		}
+		raw, err := marshalJSON(next)
+		if err != nil {
+			return fmt.Errorf("cstore: encode value: %w", err)
+		}
+		err = conditionalSet(ctx, keySlot(c.backend, key), raw, 0, true, versionIs(version))
+		if !errors.Is(err, ErrConflict) {
+			return err
+		}
+		if err := sleepContext(ctx, time.Duration(attempt+1)*10*time.Millisecond); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("cstore: update %q gave up after %d attempts: %w", key, maxUpdateAttempts, ErrConflict)
+}
+
+func jsonEqual(a, b []byte) bool {
+	if bytes.Equal(bytes.TrimSpace(a), bytes.TrimSpace(b)) {
+		return true
+	}
+	var av, bv any
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+func isNull(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null"))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
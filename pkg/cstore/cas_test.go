@@ -0,0 +1,160 @@
+package cstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore"
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore/memstore"
+)
+
+func TestCompareAndSwap(t *testing.T) {
+	client := memstore.NewClient()
+	ctx := context.Background()
+
+	swapped, err := client.CompareAndSwap(ctx, "lease", nil, counter{Count: 1})
+	if err != nil || !swapped {
+		t.Fatalf("CompareAndSwap on missing key: swapped=%v err=%v", swapped, err)
+	}
+	swapped, err = client.CompareAndSwap(ctx, "lease", nil, counter{Count: 2})
+	if err != nil || swapped {
+		t.Fatalf("expected swap to fail when key exists: swapped=%v err=%v", swapped, err)
+	}
+	swapped, err = client.CompareAndSwap(ctx, "lease", counter{Count: 9}, counter{Count: 2})
+	if err != nil || swapped {
+		t.Fatalf("expected swap to fail on value mismatch: swapped=%v err=%v", swapped, err)
+	}
+	swapped, err = client.CompareAndSwap(ctx, "lease", counter{Count: 1}, counter{Count: 2})
+	if err != nil || !swapped {
+		t.Fatalf("CompareAndSwap: swapped=%v err=%v", swapped, err)
+	}
+
+	var got counter
+	item, err := client.Get(ctx, "lease", &got)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Count != 2 || item.Version != 2 {
+		t.Fatalf("unexpected item after swaps: %#v value=%#v", item, got)
+	}
+}
+
+// racingStore lets another writer replace an unversioned value right after
+// the first read.
+type racingStore struct {
+	*memstore.Store
+	once sync.Once
+}
+
+func (s *racingStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.Store.Get(ctx, key)
+	s.once.Do(func() { err = s.Store.Set(ctx, key, []byte(`{"count":5}`), nil) })
+	return data, err
+}
+
+func TestCompareAndSwapDetectsChangeAfterRead(t *testing.T) {
+	store := &racingStore{Store: memstore.New()}
+	ctx := context.Background()
+	if err := store.Store.Set(ctx, "lease", []byte(`{"count":1}`), nil); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	client := cstore.NewWithBackend(store)
+
+	swapped, err := client.CompareAndSwap(ctx, "lease", counter{Count: 1}, counter{Count: 2})
+	if err != nil || swapped {
+		t.Fatalf("expected the swap to lose the race: swapped=%v err=%v", swapped, err)
+	}
+	var got counter
+	if _, err := client.Get(ctx, "lease", &got); err != nil || got.Count != 5 {
+		t.Fatalf("concurrent write was overwritten: %#v err=%v", got, err)
+	}
+}
+
+func TestSetIfVersionConflict(t *testing.T) {
+	client := memstore.NewClient()
+	ctx := context.Background()
+
+	zero := uint64(0)
+	if err := client.HSet(ctx, "jobs", "a", counter{Count: 1}, &cstore.SetOptions{IfVersion: &zero}); err != nil {
+		t.Fatalf("HSet IfVersion 0: %v", err)
+	}
+	err := client.HSet(ctx, "jobs", "a", counter{Count: 2}, &cstore.SetOptions{IfVersion: &zero})
+	if !errors.Is(err, cstore.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	all, err := client.HGetAll(ctx, "jobs")
+	if err != nil || len(all) != 1 || all[0].Version != 1 || string(all[0].Value) != `{"count":1}` {
+		t.Fatalf("HGetAll: %#v err=%v", all, err)
+	}
+}
+
+func TestUpdateWithoutLostWrites(t *testing.T) {
+	client := memstore.NewClient()
+	ctx := context.Background()
+
+	const workers, increments = 8, 25
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				err := client.Update(ctx, "counter", func(old json.RawMessage) (any, error) {
+					var c counter
+					if old != nil {
+						if err := json.Unmarshal(old, &c); err != nil {
+							return nil, err
+						}
+					}
+					c.Count++
+					return c, nil
+				})
+				if err != nil {
+					t.Errorf("Update: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var got counter
+	if _, err := client.Get(ctx, "counter", &got); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Count != workers*increments {
+		t.Fatalf("lost writes: got %d want %d", got.Count, workers*increments)
+	}
+}
+
+func TestUpdateOverHTTP(t *testing.T) {
+	srv := newTestCStoreServer(t)
+	defer srv.Close()
+
+	client, err := cstore.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		err := client.Update(ctx, "jobs:1", func(old json.RawMessage) (any, error) {
+			var c counter
+			if old != nil {
+				_ = json.Unmarshal(old, &c)
+			}
+			c.Count++
+			return c, nil
+		})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+	var got counter
+	item, err := client.Get(ctx, "jobs:1", &got)
+	if err != nil || got.Count != 3 || item.Version != 3 {
+		t.Fatalf("Get: %#v value=%#v err=%v", item, got, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,18 +12,27 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Ratio1/edge_sdk_go/internal/httpx"
 	"github.com/Ratio1/edge_sdk_go/internal/ratio1api"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1transport"
 )
 
 // Client provides access to the upstream CStore REST API.
 type Client struct {
 	backend Backend
+
+	// StrictNotFound makes Get and HGet return an error wrapping ErrNotFound
+	// for missing keys and hash fields instead of a nil item. HTTP 404
+	// responses and the upstream "error" result string count as missing, so
+	// a value that is the bare string "error" also reads as missing. Set it
+	// before the client is shared between goroutines.
+	StrictNotFound bool
 }
 
 // New constructs a Client bound to the provided base URL.
-func New(baseURL string, opts ...httpx.Option) (client *Client, err error) {
+func New(baseURL string, opts ...r1transport.Option) (client *Client, err error) {
 	cl, err := httpx.NewClient(baseURL, opts...)
 	if err != nil {
 		return nil, err
@@ -41,10 +51,14 @@ func NewWithBackend(b Backend) *Client {
 }
 
 // Get retrieves a value as raw JSON. Provide out to decode into a struct.
+// Missing keys yield a nil item, or ErrNotFound when StrictNotFound is set.
 func (c *Client) Get(ctx context.Context, key string, out any) (item *Item[json.RawMessage], err error) {
 	item, err = getItem[json.RawMessage](ctx, c, key)
-	if err != nil || item == nil {
-		return item, err
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, c.notFound(fmt.Sprintf("key %q", key))
 	}
 	if out != nil {
 		if err := json.Unmarshal(item.Value, out); err != nil {
@@ -60,10 +74,14 @@ func (c *Client) Set(ctx context.Context, key string, value any, opts *SetOption
 }
 
 // HGet retrieves a value stored under a hash key and decodes it into the requested type.
+// Missing fields yield a nil item, or ErrNotFound when StrictNotFound is set.
 func (c *Client) HGet(ctx context.Context, hashKey, field string, out any) (item *HashItem[json.RawMessage], err error) {
 	item, err = getHashItem[json.RawMessage](ctx, c, hashKey, field)
-	if err != nil || item == nil {
-		return item, err
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, c.notFound(fmt.Sprintf("hash key %q field %q", hashKey, field))
 	}
 	if out != nil {
 		if err := json.Unmarshal(item.Value, out); err != nil {
@@ -78,11 +96,53 @@ func (c *Client) HSet(ctx context.Context, hashKey, field string, value any, opt
 	return setHashJSONEncoded(ctx, c, hashKey, field, value, opts)
 }
 
-// HGetAll retrieves all fields stored under a hash key.
+// HGetAll retrieves all fields stored under a hash key. Fields that cannot be
+// decoded are omitted and reported as *FieldDecodeError values joined into err.
 func (c *Client) HGetAll(ctx context.Context, hashKey string) (items []HashItem[json.RawMessage], err error) {
 	return getAllHashItems[json.RawMessage](ctx, c, hashKey)
 }
 
+// Delete removes key. Deleting a missing key is not an error.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	if strings.TrimSpace(key) == "" {
+		return fmt.Errorf("cstore: key is required")
+	}
+	if c == nil || c.backend == nil {
+		return fmt.Errorf("cstore: client is nil")
+	}
+	return c.backend.Delete(ctx, key)
+}
+
+// HDel removes the given fields from a hash key. Missing fields are ignored.
+func (c *Client) HDel(ctx context.Context, hashKey string, fields ...string) error {
+	if strings.TrimSpace(hashKey) == "" {
+		return fmt.Errorf("cstore: hash key is required")
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("cstore: at least one hash field is required")
+	}
+	for i, field := range fields {
+		if strings.TrimSpace(field) == "" {
+			return fmt.Errorf("cstore: hash field at index %d is empty", i)
+		}
+	}
+	if c == nil || c.backend == nil {
+		return fmt.Errorf("cstore: client is nil")
+	}
+	return c.backend.HDel(ctx, hashKey, fields...)
+}
+
+// HClear removes every field stored under a hash key.
+func (c *Client) HClear(ctx context.Context, hashKey string) error {
+	if strings.TrimSpace(hashKey) == "" {
+		return fmt.Errorf("cstore: hash key is required")
+	}
+	if c == nil || c.backend == nil {
+		return fmt.Errorf("cstore: client is nil")
+	}
+	return c.backend.HClear(ctx, hashKey)
+}
+
 // GetStatus returns the payload exposed by the /get_status endpoint.
 func (c *Client) GetStatus(ctx context.Context) (status *Status, err error) {
 	if c == nil || c.backend == nil {
@@ -102,11 +162,37 @@ func (c *Client) GetStatus(ctx context.Context) (status *Status, err error) {
 	}
 	return &statusValue, nil
 }
+
+func (c *Client) notFound(what string) error {
+	if !c.StrictNotFound {
+		return nil
+	}
+	return fmt.Errorf("cstore: %s: %w", what, ErrNotFound)
+}
+
+// upstreamMissing reports whether a get or hget outcome is the upstream's way
+// of saying the key does not exist: an HTTP 404 or the bare "error" result
+// string. Only strict clients treat these as missing keys.
+func upstreamMissing(data []byte, err error) bool {
+	if err != nil {
+		var httpErr *httpx.HTTPError
+		return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+	}
+	var str string
+	if json.Unmarshal(bytes.TrimSpace(data), &str) != nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(str), "error")
+}
+
 func getItem[T any](ctx context.Context, client *Client, key string) (*Item[T], error) {
 	if client == nil || client.backend == nil {
 		return nil, fmt.Errorf("cstore: client is nil")
 	}
 	data, err := client.backend.Get(ctx, key)
+	if client.StrictNotFound && upstreamMissing(data, err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +218,13 @@ func setRawJSON(ctx context.Context, client *Client, key string, payload []byte,
 
 	raw := append([]byte(nil), bytes.TrimSpace(payload)...)
 
+	if opts != nil && opts.IfVersion != nil {
+		return conditionalSet(ctx, keySlot(client.backend, key), raw, opts.TTL, false, versionIs(*opts.IfVersion))
+	}
+	raw, err := applyTTL(raw, opts)
+	if err != nil {
+		return err
+	}
 	return client.backend.Set(ctx, key, raw, opts)
 }
 
@@ -146,6 +239,9 @@ func getHashItem[T any](ctx context.Context, client *Client, hashKey, field stri
 		return nil, fmt.Errorf("cstore: client is nil")
 	}
 	data, err := client.backend.HGet(ctx, hashKey, field)
+	if client.StrictNotFound && upstreamMissing(data, err) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -188,20 +284,42 @@ func setHashRawJSON(ctx context.Context, client *Client, hashKey, field string,
 
 	raw := append([]byte(nil), bytes.TrimSpace(payload)...)
 
+	if opts != nil && opts.IfVersion != nil {
+		return conditionalSet(ctx, hashSlot(client.backend, hashKey, field), raw, opts.TTL, false, versionIs(*opts.IfVersion))
+	}
+	raw, err := applyTTL(raw, opts)
+	if err != nil {
+		return err
+	}
 	return client.backend.HSet(ctx, hashKey, field, raw, opts)
 }
 
+// applyTTL wraps raw in an expiry envelope when opts requests a TTL.
+func applyTTL(raw []byte, opts *SetOptions) ([]byte, error) {
+	if opts == nil || opts.TTL == 0 {
+		return raw, nil
+	}
+	if opts.TTL < 0 {
+		return nil, fmt.Errorf("cstore: TTL must not be negative")
+	}
+	return wrapEnvelope(raw, envelopeMeta{ExpiresAt: expiresAt(opts.TTL)})
+}
+
 func decodeItem[T any](key string, data []byte) (*Item[T], error) {
 	trimmed := bytes.TrimSpace(data)
 	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
 		return nil, nil
 	}
 
+	raw, meta := unwrapEnvelope(trimmed)
+	if meta.expired(time.Now()) {
+		return nil, nil
+	}
 	var value T
-	if err := json.Unmarshal(trimmed, &value); err != nil {
+	if err := json.Unmarshal(raw, &value); err != nil {
 		return nil, fmt.Errorf("cstore: decode value: %w", err)
 	}
-	return &Item[T]{Key: key, Value: value}, nil
+	return &Item[T]{Key: key, Value: value, Version: versionOf(meta), ExpiresAt: meta.expiry()}, nil
 }
 
 func decodeHashItem[T any](hashKey, field string, data []byte) (*HashItem[T], error) {
@@ -210,11 +328,15 @@ func decodeHashItem[T any](hashKey, field string, data []byte) (*HashItem[T], er
 		return nil, nil
 	}
 
+	raw, meta := unwrapEnvelope(trimmed)
+	if meta.expired(time.Now()) {
+		return nil, nil
+	}
 	var value T
-	if err := json.Unmarshal(trimmed, &value); err != nil {
+	if err := json.Unmarshal(raw, &value); err != nil {
 		return nil, fmt.Errorf("cstore: decode hash value: %w", err)
 	}
-	return &HashItem[T]{HashKey: hashKey, Field: field, Value: value}, nil
+	return &HashItem[T]{HashKey: hashKey, Field: field, Value: value, Version: versionOf(meta), ExpiresAt: meta.expiry()}, nil
 }
 
 func decodeHashItems[T any](hashKey string, data []byte) ([]HashItem[T], error) {
@@ -238,14 +360,24 @@ func decodeHashItems[T any](hashKey string, data []byte) ([]HashItem[T], error)
 	sort.Strings(fields)
 
 	items := make([]HashItem[T], 0, len(fields))
+	var errs []error
+	now := time.Now()
 	for _, field := range fields {
+		fieldRaw, meta := unwrapEnvelope(raw[field])
+		if isNull(fieldRaw) || meta.expired(now) {
+			continue
+		}
 		var value T
-		if err := json.Unmarshal(raw[field], &value); err != nil {
-			return nil, fmt.Errorf("cstore: decode hash field %q: %w", field, err)
+		if err := json.Unmarshal(fieldRaw, &value); err != nil {
+			errs = append(errs, &FieldDecodeError{HashKey: hashKey, Field: field, Err: err})
+			continue
 		}
-		items = append(items, HashItem[T]{HashKey: hashKey, Field: field, Value: value})
+		items = append(items, HashItem[T]{HashKey: hashKey, Field: field, Value: value, Version: versionOf(meta), ExpiresAt: meta.expiry()})
+	}
+	if len(items) == 0 {
+		items = nil
 	}
-	return items, nil
+	return items, errors.Join(errs...)
 }
 
 func marshalJSON(value any) ([]byte, error) {
@@ -297,8 +429,11 @@ func decodeBoolResult(body []byte) (bool, error) {
 type Backend interface {
 	Get(ctx context.Context, key string) (data []byte, err error)
 	Set(ctx context.Context, key string, raw []byte, opts *SetOptions) error
+	Delete(ctx context.Context, key string) error
 	HGet(ctx context.Context, hashKey, field string) (data []byte, err error)
 	HSet(ctx context.Context, hashKey, field string, raw []byte, opts *SetOptions) error
+	HDel(ctx context.Context, hashKey string, fields ...string) error
+	HClear(ctx context.Context, hashKey string) error
 	HGetAll(ctx context.Context, hashKey string) (data []byte, err error)
 	GetStatus(ctx context.Context) (statusPayload []byte, err error)
 }
@@ -379,6 +514,50 @@ func (b *httpBackend) Set(ctx context.Context, key string, raw []byte, opts *Set
 	return nil
 }
 
+// tombstone is written in place of deleted entries because the upstream REST
+// API has no delete endpoint; reads already treat null values as missing.
+var tombstone = []byte("null")
+
+// Delete writes a null tombstone over key. The HTTP API cannot remove keys, so
+// the key keeps appearing in get_status listings with a null value until it is
+// set again.
+func (b *httpBackend) Delete(ctx context.Context, key string) error {
+	return b.Set(ctx, key, tombstone, nil)
+}
+
+// HDel writes a null tombstone over each field.
+func (b *httpBackend) HDel(ctx context.Context, hashKey string, fields ...string) error {
+	for _, field := range fields {
+		if err := b.HSet(ctx, hashKey, field, tombstone, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HClear tombstones every field currently stored under hashKey.
+func (b *httpBackend) HClear(ctx context.Context, hashKey string) error {
+	data, err := b.HGetAll(ctx, hashKey)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 || bytes.Equal(bytes.TrimSpace(data), tombstone) {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("cstore: decode hash map: %w", err)
+	}
+	names := make([]string, 0, len(fields))
+	for field, raw := range fields {
+		if !bytes.Equal(bytes.TrimSpace(raw), tombstone) {
+			names = append(names, field)
+		}
+	}
+	sort.Strings(names)
+	return b.HDel(ctx, hashKey, names...)
+}
+
 func (b *httpBackend) GetStatus(ctx context.Context) (statusPayload []byte, err error) {
 	if b == nil || b.client == nil {
 		return nil, fmt.Errorf("cstore: http backend not configured")
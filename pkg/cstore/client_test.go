@@ -3,6 +3,7 @@ package cstore_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net"
 	"net/http"
 	"sort"
@@ -418,3 +419,68 @@ func newLocalHTTPServer(t *testing.T, handler http.Handler) *testServer {
 	}()
 	return ts
 }
+
+func TestClientStrictNotFound(t *testing.T) {
+	srv := newTestCStoreServer(t)
+	defer srv.Close()
+
+	client, err := cstore.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	client.StrictNotFound = true
+
+	ctx := context.Background()
+	if _, err := client.Get(ctx, "missing", nil); !errors.Is(err, cstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing key, got %v", err)
+	}
+	if _, err := client.HGet(ctx, "jobs", "missing", nil); !errors.Is(err, cstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing hash field, got %v", err)
+	}
+
+	if err := client.Set(ctx, "present", counter{Count: 1}, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	item, err := client.Get(ctx, "present", nil)
+	if err != nil || item == nil {
+		t.Fatalf("Get present: item=%#v err=%v", item, err)
+	}
+}
+
+func TestClientStrictNotFoundClassifiesUpstreamErrors(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/get":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"detail":"not found"}`))
+		case "/hget":
+			_, _ = w.Write([]byte(`{"result":"error"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv := newLocalHTTPServer(t, handler)
+	defer srv.Close()
+
+	client, err := cstore.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.Get(ctx, "missing", nil); err == nil || errors.Is(err, cstore.ErrNotFound) {
+		t.Fatalf("lenient Get: expected the HTTP error, got %v", err)
+	}
+	if _, err := client.HGet(ctx, "jobs", "missing", nil); errors.Is(err, cstore.ErrNotFound) {
+		t.Fatalf("lenient HGet: unexpected ErrNotFound")
+	}
+
+	client.StrictNotFound = true
+	if _, err := client.Get(ctx, "missing", nil); !errors.Is(err, cstore.ErrNotFound) {
+		t.Fatalf("Get: expected ErrNotFound for a 404, got %v", err)
+	}
+	if _, err := client.HGet(ctx, "jobs", "missing", nil); !errors.Is(err, cstore.ErrNotFound) {
+		t.Fatalf("HGet: expected ErrNotFound for the error string, got %v", err)
+	}
+}
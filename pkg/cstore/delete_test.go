@@ -0,0 +1,79 @@
+package cstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore"
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore/memstore"
+)
+
+func TestDeleteOperations(t *testing.T) {
+	srv := newTestCStoreServer(t)
+	defer srv.Close()
+	httpClient, err := cstore.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	clients := map[string]*cstore.Client{
+		"http":     httpClient,
+		"memstore": memstore.NewClient(),
+	}
+	for name, client := range clients {
+		client := client
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if err := client.Set(ctx, "jobs:1", counter{Count: 1}, nil); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if err := client.Delete(ctx, "jobs:1"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if item, err := client.Get(ctx, "jobs:1", nil); err != nil || item != nil {
+				t.Fatalf("expected deleted key to be missing, got %#v err=%v", item, err)
+			}
+			if err := client.Delete(ctx, "never-set"); err != nil {
+				t.Fatalf("Delete missing: %v", err)
+			}
+
+			for _, field := range []string{"a", "b", "c"} {
+				if err := client.HSet(ctx, "jobs", field, counter{Count: 1}, nil); err != nil {
+					t.Fatalf("HSet %s: %v", field, err)
+				}
+			}
+			if err := client.HDel(ctx, "jobs", "a", "b"); err != nil {
+				t.Fatalf("HDel: %v", err)
+			}
+			all, err := client.HGetAll(ctx, "jobs")
+			if err != nil || len(all) != 1 || all[0].Field != "c" {
+				t.Fatalf("HGetAll after HDel: %#v err=%v", all, err)
+			}
+			if item, err := client.HGet(ctx, "jobs", "a", nil); err != nil || item != nil {
+				t.Fatalf("expected deleted field to be missing, got %#v err=%v", item, err)
+			}
+
+			if err := client.HClear(ctx, "jobs"); err != nil {
+				t.Fatalf("HClear: %v", err)
+			}
+			all, err = client.HGetAll(ctx, "jobs")
+			if err != nil || len(all) != 0 {
+				t.Fatalf("HGetAll after HClear: %#v err=%v", all, err)
+			}
+		})
+	}
+}
+
+func TestDeleteRequiresArguments(t *testing.T) {
+	client := memstore.NewClient()
+	ctx := context.Background()
+	if err := client.Delete(ctx, " "); err == nil {
+		t.Fatalf("expected error for empty key")
+	}
+	if err := client.HDel(ctx, "jobs"); err == nil {
+		t.Fatalf("expected error when no fields are given")
+	}
+	if err := client.HClear(ctx, ""); err == nil {
+		t.Fatalf("expected error for empty hash key")
+	}
+}
@@ -2,7 +2,12 @@
 // API. The HTTP surface mirrors the FastAPI plugin implemented in
 // extensions/business/cstore/cstore_manager_api.py within the Ratio1 edge_node
 // repository. The public Go API centres around the Client type, which exposes
-// Set/Get/HSet/HGet/HGetAll/GetStatus with optional decoding targets while
-// documenting gaps where the upstream REST implementation currently lacks
-// features such as TTL or conditional headers.
+// Set/Get/HSet/HGet/HGetAll/GetStatus with optional decoding targets. Where the
+// upstream REST implementation lacks write controls, the client emulates them:
+// optimistic concurrency (SetOptions.IfVersion, CompareAndSwap, Update) and
+// expiry (SetOptions.TTL, PurgeExpired) keep a small metadata envelope inside
+// the stored value that reads unwrap transparently, and deletes are written as
+// null tombstones that reads treat as missing. Conditional writes are atomic
+// only on backends implementing CompareAndSwapBackend; over HTTP they are best
+// effort.
 package cstore
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1transport"
 )
 
 const (
@@ -11,17 +13,24 @@ const (
 )
 
 // NewFromEnv initialises a Client using the live CStore manager URL exported by
-// Ratio1 nodes. It fails when the environment variable is unset.
-func NewFromEnv() (client *Client, err error) {
+// Ratio1 nodes. It fails when the environment variable is unset. Optional
+// transport options are forwarded to New after the TLS settings read from the
+// EE_R1_TLS_* variables (see r1transport.TLSConfigFromEnv), so they take
+// precedence.
+func NewFromEnv(opts ...r1transport.Option) (client *Client, err error) {
 	baseURL := strings.TrimSpace(os.Getenv(envCStoreURL))
 	if baseURL == "" {
 		return nil, fmt.Errorf("cstore: HTTP env requires %s", envCStoreURL)
 	}
-	return newHTTPClient(baseURL)
+	envOpts, err := r1transport.EnvOptions()
+	if err != nil {
+		return nil, fmt.Errorf("cstore: %w", err)
+	}
+	return newHTTPClient(baseURL, append(envOpts, opts...)...)
 }
 
-func newHTTPClient(baseURL string) (*Client, error) {
-	client, err := New(baseURL)
+func newHTTPClient(baseURL string, opts ...r1transport.Option) (*Client, error) {
+	client, err := New(baseURL, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cstore: init HTTP client: %w", err)
 	}
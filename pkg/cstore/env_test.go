@@ -2,11 +2,24 @@ package cstore_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/Ratio1/edge_sdk_go/pkg/cstore"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1transport"
 )
 
 func TestNewFromEnvHTTP(t *testing.T) {
@@ -58,3 +71,105 @@ func TestNewFromEnvInvalidURL(t *testing.T) {
 		t.Fatalf("expected error for invalid URL")
 	}
 }
+
+func TestNewFromEnvTransportOptions(t *testing.T) {
+	var gotAuth string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("null"))
+	})
+	srv := newLocalHTTPServer(t, handler)
+	defer srv.Close()
+
+	t.Setenv("EE_CHAINSTORE_API_URL", srv.URL)
+
+	client, err := cstore.NewFromEnv(
+		r1transport.WithHeaders(http.Header{"Authorization": {"Bearer token"}}),
+		r1transport.WithHTTPClient(&http.Client{Timeout: time.Second}),
+		r1transport.WithRetryPolicy(r1transport.RetryPolicy{MaxRetries: 0}),
+	)
+	if err != nil {
+		t.Fatalf("NewFromEnv: %v", err)
+	}
+	if _, err := client.Get(context.Background(), "missing", nil); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if gotAuth != "Bearer token" {
+		t.Fatalf("expected custom header to be forwarded, got %q", gotAuth)
+	}
+}
+
+func TestNewFromEnvTLS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "cstore.r1.internal"},
+		DNSNames:              []string{"cstore.r1.internal"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write CA: %v", err)
+	}
+
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("network disabled for tests: %v", err)
+	}
+	tlsLn := tls.NewListener(ln, &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS13,
+	})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("null"))
+	})}
+	go func() { _ = srv.Serve(tlsLn) }()
+	defer srv.Close()
+
+	t.Setenv("EE_CHAINSTORE_API_URL", "https://"+ln.Addr().String())
+	t.Setenv("EE_R1_TLS_SERVER_NAME", "cstore.r1.internal")
+	t.Setenv("EE_R1_TLS_MIN_VERSION", "1.3")
+	noRetry := r1transport.WithRetryPolicy(r1transport.RetryPolicy{MaxRetries: 0})
+
+	// Without the CA bundle the server certificate is not trusted.
+	client, err := cstore.NewFromEnv(noRetry)
+	if err != nil {
+		t.Fatalf("NewFromEnv: %v", err)
+	}
+	if _, err := client.Get(context.Background(), "missing", nil); err == nil {
+		t.Fatalf("expected an untrusted certificate error")
+	}
+
+	t.Setenv("EE_R1_TLS_CA", caFile)
+	client, err = cstore.NewFromEnv(noRetry)
+	if err != nil {
+		t.Fatalf("NewFromEnv: %v", err)
+	}
+	if _, err := client.Get(context.Background(), "missing", nil); err != nil {
+		t.Fatalf("Get over TLS: %v", err)
+	}
+
+	t.Setenv("EE_R1_TLS_MIN_VERSION", "1.4")
+	if _, err := cstore.NewFromEnv(); err == nil {
+		t.Fatalf("expected an error for an unknown TLS version")
+	}
+	t.Setenv("EE_R1_TLS_MIN_VERSION", "")
+	t.Setenv("EE_R1_TLS_CERT", caFile)
+	if _, err := cstore.NewFromEnv(); err == nil {
+		t.Fatalf("expected an error for a certificate without a key")
+	}
+}
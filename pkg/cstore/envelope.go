@@ -0,0 +1,96 @@
+package cstore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// envelopeKey marks values written by the client with write controls. The
+// upstream REST API stores opaque JSON, so versions live inside the value.
+const envelopeKey = "_r1_cstore"
+
+// envelope wraps a stored value together with client-maintained metadata.
+type envelope struct {
+	Meta  envelopeMeta    `json:"_r1_cstore"`
+	Value json.RawMessage `json:"value"`
+}
+
+type envelopeMeta struct {
+	Version uint64 `json:"version,omitempty"`
+	Token   string `json:"token,omitempty"`
+	// ExpiresAt is the Unix time in milliseconds after which the value is
+	// treated as absent. Zero means the value never expires.
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+}
+
+func (m *envelopeMeta) expired(now time.Time) bool {
+	return m != nil && m.ExpiresAt > 0 && now.UnixMilli() >= m.ExpiresAt
+}
+
+func (m *envelopeMeta) expiry() time.Time {
+	if m == nil || m.ExpiresAt == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(m.ExpiresAt)
+}
+
+func expiresAt(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return time.Now().Add(ttl).UnixMilli()
+}
+
+// unwrapEnvelope returns the user value and metadata stored in data. Values
+// that were not written through an envelope are returned unchanged with a nil
+// meta.
+func unwrapEnvelope(data []byte) (value []byte, meta *envelopeMeta) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return data, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &fields); err != nil || len(fields) != 2 {
+		return data, nil
+	}
+	rawMeta, ok := fields[envelopeKey]
+	if !ok {
+		return data, nil
+	}
+	rawValue, ok := fields["value"]
+	if !ok {
+		return data, nil
+	}
+	var m envelopeMeta
+	if err := json.Unmarshal(rawMeta, &m); err != nil {
+		return data, nil
+	}
+	return rawValue, &m
+}
+
+func wrapEnvelope(value []byte, meta envelopeMeta) ([]byte, error) {
+	data, err := marshalJSON(envelope{Meta: meta, Value: json.RawMessage(value)})
+	if err != nil {
+		return nil, fmt.Errorf("cstore: encode envelope: %w", err)
+	}
+	return data, nil
+}
+
+func newWriteToken() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("cstore: generate write token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func versionOf(meta *envelopeMeta) uint64 {
+	if meta == nil {
+		return 0
+	}
+	return meta.Version
+}
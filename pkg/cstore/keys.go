@@ -0,0 +1,261 @@
+package cstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Keys lists the keys reported by GetStatus, filtered by prefix and glob
+// pattern, sorted, and paged from opts.Cursor. The upstream returns every key
+// at once, so filtering and paging happen client-side and work against any
+// Backend. Each call fetches a fresh GetStatus; use Scan to walk every page
+// from a single listing.
+//
+// Over HTTP /get_status still lists keys removed with Delete, which leaves a
+// null tombstone, and keys whose TTL has expired. Unless opts.IncludeDeleted
+// is set, candidate keys are read with Get, in order and only until the page
+// is full, and those reported missing are left out.
+func (c *Client) Keys(ctx context.Context, opts KeysOptions) (page *KeysPage, err error) {
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("cstore: limit must not be negative")
+	}
+	keys, err := c.matchingKeys(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	page = &KeysPage{Keys: make([]string, 0, len(keys))}
+	for _, key := range keys {
+		ok, err := c.listed(ctx, key, opts)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if opts.Limit > 0 && len(page.Keys) == opts.Limit {
+			page.NextCursor = encodeCursor(page.Keys[len(page.Keys)-1])
+			break
+		}
+		page.Keys = append(page.Keys, key)
+	}
+	return page, nil
+}
+
+// listed reports whether key belongs in a listing for opts.
+func (c *Client) listed(ctx context.Context, key string, opts KeysOptions) (bool, error) {
+	if opts.IncludeDeleted {
+		return true, nil
+	}
+	data, err := c.backend.Get(ctx, key)
+	if c.StrictNotFound && upstreamMissing(data, err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, tombstone) {
+		return false, nil
+	}
+	_, meta := unwrapEnvelope(trimmed)
+	return !meta.expired(time.Now()), nil
+}
+
+// matchingKeys returns the sorted, de-duplicated keys matching opts that sort
+// after opts.Cursor.
+func (c *Client) matchingKeys(ctx context.Context, opts KeysOptions) ([]string, error) {
+	var match func(string) bool
+	if opts.Pattern != "" {
+		re, err := compileGlob(opts.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		match = re.MatchString
+	}
+	after, err := decodeCursor(opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := c.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var all []string
+	if status != nil {
+		all = status.Keys
+	}
+
+	keys := make([]string, 0, len(all))
+	for _, key := range all {
+		if !strings.HasPrefix(key, opts.Prefix) {
+			continue
+		}
+		if match != nil && !match(key) {
+			continue
+		}
+		if opts.Cursor != "" && key <= after {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return dedupeSorted(keys), nil
+}
+
+// KeyScanner iterates over keys. Use it like bufio.Scanner:
+//
+//	sc := client.Scan(ctx, cstore.KeysOptions{Prefix: "jobs:"})
+//	for sc.Next() {
+//		fmt.Println(sc.Key())
+//	}
+//	if err := sc.Err(); err != nil { ... }
+type KeyScanner struct {
+	ctx    context.Context
+	client *Client
+	opts   KeysOptions
+
+	keys    []string
+	key     string
+	fetched bool
+	err     error
+}
+
+// Scan returns a KeyScanner over the keys matching opts, starting after
+// opts.Cursor. The first call to Next fetches every key with a single
+// GetStatus call, which cannot be paged upstream, and the matches are walked
+// in memory; opts.Limit is ignored. Deleted and expired keys are skipped as in
+// Keys, reading each key with Get as Next reaches it.
+func (c *Client) Scan(ctx context.Context, opts KeysOptions) *KeyScanner {
+	return &KeyScanner{ctx: ctx, client: c, opts: opts}
+}
+
+// Next advances to the next key. It returns false when the listing is
+// exhausted or an error occurred.
+func (s *KeyScanner) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	if !s.fetched {
+		s.fetched = true
+		s.keys, s.err = s.client.matchingKeys(s.ctx, s.opts)
+		if s.err != nil {
+			return false
+		}
+	}
+	for len(s.keys) > 0 {
+		key := s.keys[0]
+		s.keys = s.keys[1:]
+		ok, err := s.client.listed(s.ctx, key, s.opts)
+		if err != nil {
+			s.err = err
+			return false
+		}
+		if ok {
+			s.key = key
+			return true
+		}
+	}
+	return false
+}
+
+// Key returns the current key.
+func (s *KeyScanner) Key() string {
+	return s.key
+}
+
+// Err returns the first error encountered while scanning.
+func (s *KeyScanner) Err() error {
+	return s.err
+}
+
+func encodeCursor(lastKey string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastKey))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", fmt.Errorf("cstore: invalid cursor: %w", err)
+	}
+	return string(data), nil
+}
+
+// compileGlob translates a Redis-style glob into an anchored regular
+// expression. Unlike path.Match, '*' also matches separators such as '/' and
+// ':' so patterns like "jobs:*" behave as users expect.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	runes := []rune(pattern)
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(runes); i++ {
+		switch ch := runes[i]; ch {
+		case '*':
+			b.WriteString("(?s:.*)")
+		case '?':
+			b.WriteString("(?s:.)")
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			// Inside a class '\' escapes the next character, so `[\]]`
+			// matches a literal ']'.
+			var class strings.Builder
+			end := -1
+			for j := i + 1; j < len(runes); j++ {
+				r := runes[j]
+				if r == ']' {
+					end = j
+					break
+				}
+				if r == '\\' && j+1 < len(runes) {
+					j++
+					r = runes[j]
+					if strings.ContainsRune(`\]^-[`, r) {
+						class.WriteByte('\\')
+					}
+				} else if r == '!' && j == i+1 {
+					r = '^'
+				}
+				class.WriteRune(r)
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("cstore: invalid pattern %q: unterminated character class", pattern)
+			}
+			b.WriteString("[" + class.String() + "]")
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("cstore: invalid pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+func dedupeSorted(keys []string) []string {
+	if len(keys) < 2 {
+		return keys
+	}
+	out := keys[:1]
+	for _, key := range keys[1:] {
+		if key != out[len(out)-1] {
+			out = append(out, key)
+		}
+	}
+	return out
+}
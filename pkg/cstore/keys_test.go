@@ -0,0 +1,200 @@
+package cstore_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore"
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore/memstore"
+)
+
+func newKeysClient(t *testing.T) *cstore.Client {
+	t.Helper()
+	client := memstore.NewClient()
+	ctx := context.Background()
+	for _, key := range []string{"jobs:3", "jobs:1", "jobs:2", "jobs:10", "users:1", "jobs/archive:1"} {
+		if err := client.Set(ctx, key, 1, nil); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+	return client
+}
+
+func TestKeysFiltering(t *testing.T) {
+	client := newKeysClient(t)
+	ctx := context.Background()
+
+	cases := []struct {
+		name string
+		opts cstore.KeysOptions
+		want []string
+	}{
+		{name: "all", opts: cstore.KeysOptions{}, want: []string{"jobs/archive:1", "jobs:1", "jobs:10", "jobs:2", "jobs:3", "users:1"}},
+		{name: "prefix", opts: cstore.KeysOptions{Prefix: "jobs:"}, want: []string{"jobs:1", "jobs:10", "jobs:2", "jobs:3"}},
+		{name: "star", opts: cstore.KeysOptions{Pattern: "jobs*"}, want: []string{"jobs/archive:1", "jobs:1", "jobs:10", "jobs:2", "jobs:3"}},
+		{name: "question", opts: cstore.KeysOptions{Pattern: "jobs:?"}, want: []string{"jobs:1", "jobs:2", "jobs:3"}},
+		{name: "class", opts: cstore.KeysOptions{Pattern: "jobs:[12]*"}, want: []string{"jobs:1", "jobs:10", "jobs:2"}},
+		{name: "negated class", opts: cstore.KeysOptions{Pattern: "*:[!1]"}, want: []string{"jobs:2", "jobs:3"}},
+	}
+	for _, tc := range cases {
+		page, err := client.Keys(ctx, tc.opts)
+		if err != nil {
+			t.Fatalf("%s: Keys: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(page.Keys, tc.want) || page.NextCursor != "" {
+			t.Fatalf("%s: got %v (cursor %q) want %v", tc.name, page.Keys, page.NextCursor, tc.want)
+		}
+	}
+
+	if _, err := client.Keys(ctx, cstore.KeysOptions{Pattern: "jobs:[1"}); err == nil {
+		t.Fatalf("expected error for invalid pattern")
+	}
+}
+
+func TestKeysGlobEscapes(t *testing.T) {
+	client := memstore.NewClient()
+	ctx := context.Background()
+	for _, key := range []string{"a]", "a\\", "a-", "ab", "a^", "a*"} {
+		if err := client.Set(ctx, key, 1, nil); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+	cases := map[string][]string{
+		`a[\]]`:   {"a]"},
+		`a[\]b]`:  {"a]", "ab"},
+		`a[!\]]`:  {"a*", "a-", "a\\", "a^", "ab"},
+		`a[\\]`:   {"a\\"},
+		`a[\-\^]`: {"a-", "a^"},
+		`a\*`:     {"a*"},
+	}
+	for pattern, want := range cases {
+		page, err := client.Keys(ctx, cstore.KeysOptions{Pattern: pattern})
+		if err != nil {
+			t.Fatalf("%s: Keys: %v", pattern, err)
+		}
+		if !reflect.DeepEqual(page.Keys, want) {
+			t.Fatalf("%s: got %q want %q", pattern, page.Keys, want)
+		}
+	}
+	if _, err := client.Keys(ctx, cstore.KeysOptions{Pattern: `a[\]`}); err == nil {
+		t.Fatalf("expected error for a class closed only by an escaped bracket")
+	}
+}
+
+func TestKeysSkipDeletedAndExpired(t *testing.T) {
+	store := memstore.New()
+	client := cstore.NewWithBackend(store)
+	ctx := context.Background()
+	for _, key := range []string{"jobs:1", "jobs:3", "jobs:5"} {
+		if err := client.Set(ctx, key, 1, nil); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+	// What the HTTP backend leaves behind after Delete and after a TTL runs out.
+	if err := store.Set(ctx, "jobs:2", []byte("null"), nil); err != nil {
+		t.Fatalf("Set tombstone: %v", err)
+	}
+	if err := store.Set(ctx, "jobs:4", []byte(`{"_r1_cstore":{"expires_at":1},"value":1}`), nil); err != nil {
+		t.Fatalf("Set expired: %v", err)
+	}
+
+	first, err := client.Keys(ctx, cstore.KeysOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("Keys: %v", err)
+	}
+	if !reflect.DeepEqual(first.Keys, []string{"jobs:1", "jobs:3"}) || first.NextCursor == "" {
+		t.Fatalf("unexpected first page: %#v", first)
+	}
+	second, err := client.Keys(ctx, cstore.KeysOptions{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("Keys: %v", err)
+	}
+	if !reflect.DeepEqual(second.Keys, []string{"jobs:5"}) || second.NextCursor != "" {
+		t.Fatalf("unexpected second page: %#v", second)
+	}
+
+	var scanned []string
+	sc := client.Scan(ctx, cstore.KeysOptions{})
+	for sc.Next() {
+		scanned = append(scanned, sc.Key())
+	}
+	if err := sc.Err(); err != nil || !reflect.DeepEqual(scanned, []string{"jobs:1", "jobs:3", "jobs:5"}) {
+		t.Fatalf("Scan got %v err=%v", scanned, err)
+	}
+
+	all, err := client.Keys(ctx, cstore.KeysOptions{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("Keys: %v", err)
+	}
+	if want := []string{"jobs:1", "jobs:2", "jobs:3", "jobs:4", "jobs:5"}; !reflect.DeepEqual(all.Keys, want) {
+		t.Fatalf("IncludeDeleted got %v want %v", all.Keys, want)
+	}
+}
+
+func TestKeysPagination(t *testing.T) {
+	client := newKeysClient(t)
+	ctx := context.Background()
+
+	opts := cstore.KeysOptions{Prefix: "jobs:", Limit: 3}
+	first, err := client.Keys(ctx, opts)
+	if err != nil {
+		t.Fatalf("Keys: %v", err)
+	}
+	if !reflect.DeepEqual(first.Keys, []string{"jobs:1", "jobs:10", "jobs:2"}) || first.NextCursor == "" {
+		t.Fatalf("unexpected first page: %#v", first)
+	}
+	opts.Cursor = first.NextCursor
+	second, err := client.Keys(ctx, opts)
+	if err != nil {
+		t.Fatalf("Keys: %v", err)
+	}
+	if !reflect.DeepEqual(second.Keys, []string{"jobs:3"}) || second.NextCursor != "" {
+		t.Fatalf("unexpected second page: %#v", second)
+	}
+
+	if _, err := client.Keys(ctx, cstore.KeysOptions{Cursor: "%%%"}); err == nil {
+		t.Fatalf("expected error for invalid cursor")
+	}
+}
+
+func TestScan(t *testing.T) {
+	store := &statusCounter{Store: memstore.New()}
+	client := cstore.NewWithBackend(store)
+	ctx := context.Background()
+	var want []string
+	for i := 0; i < 25; i++ {
+		key := fmt.Sprintf("jobs:%03d", i)
+		want = append(want, key)
+		if err := client.Set(ctx, key, i, nil); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+
+	var got []string
+	sc := client.Scan(ctx, cstore.KeysOptions{Pattern: "jobs:*"})
+	for sc.Next() {
+		got = append(got, sc.Key())
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Scan got %v want %v", got, want)
+	}
+	if store.calls != 1 {
+		t.Fatalf("Scan fetched the listing %d times, want 1", store.calls)
+	}
+}
+
+// statusCounter counts GetStatus calls.
+type statusCounter struct {
+	*memstore.Store
+	calls int
+}
+
+func (s *statusCounter) GetStatus(ctx context.Context) ([]byte, error) {
+	s.calls++
+	return s.Store.GetStatus(ctx)
+}
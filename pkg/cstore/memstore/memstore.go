@@ -0,0 +1,322 @@
+// Package memstore provides an in-memory cstore.Backend for unit tests and
+// offline development. It reproduces the observable behaviour of the upstream
+// CStore manager as seen through the HTTP backend: missing keys and hash fields
+// read back as null, empty hashes are reported as null by HGetAll, and values
+// stored as JSON-encoded strings are unwrapped the same way the REST envelope
+// decoding does.
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/Ratio1/edge_sdk_go/internal/ratio1api"
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore"
+)
+
+// Store is a concurrency-safe in-memory implementation of cstore.Backend.
+type Store struct {
+	mu     sync.RWMutex
+	values map[string]json.RawMessage
+	hashes map[string]map[string]json.RawMessage
+}
+
+var (
+	_ cstore.Backend               = (*Store)(nil)
+	_ cstore.CompareAndSwapBackend = (*Store)(nil)
+)
+
+// New returns an empty Store.
+func New() *Store {
+	return &Store{
+		values: make(map[string]json.RawMessage),
+		hashes: make(map[string]map[string]json.RawMessage),
+	}
+}
+
+// NewClient returns a cstore.Client backed by a fresh Store.
+func NewClient() *cstore.Client {
+	return cstore.NewWithBackend(New())
+}
+
+// Get returns the value stored under key, or nil when the key is missing.
+func (s *Store) Get(ctx context.Context, key string) (data []byte, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	value, ok := s.values[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	return unwrapResult(value)
+}
+
+// Set stores raw JSON under key, replacing any previous value.
+func (s *Store) Set(ctx context.Context, key string, raw []byte, opts *cstore.SetOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	value, err := normalizeJSON(raw)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.values[key] = value
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete removes key.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.values, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// HGet returns the value stored under hashKey/field, or nil when missing.
+func (s *Store) HGet(ctx context.Context, hashKey, field string) (data []byte, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	value, ok := s.hashes[hashKey][field]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	return unwrapResult(value)
+}
+
+// HSet stores raw JSON under hashKey/field, replacing any previous value.
+func (s *Store) HSet(ctx context.Context, hashKey, field string, raw []byte, opts *cstore.SetOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	value, err := normalizeJSON(raw)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	bucket := s.hashes[hashKey]
+	if bucket == nil {
+		bucket = make(map[string]json.RawMessage)
+		s.hashes[hashKey] = bucket
+	}
+	bucket[field] = value
+	s.mu.Unlock()
+	return nil
+}
+
+// HDel removes fields from hashKey, dropping the hash once it is empty.
+func (s *Store) HDel(ctx context.Context, hashKey string, fields ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bucket := s.hashes[hashKey]
+	for _, field := range fields {
+		delete(bucket, field)
+	}
+	if len(bucket) == 0 {
+		delete(s.hashes, hashKey)
+	}
+	return nil
+}
+
+// HClear removes every field stored under hashKey.
+func (s *Store) HClear(ctx context.Context, hashKey string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.hashes, hashKey)
+	s.mu.Unlock()
+	return nil
+}
+
+// HGetAll returns every field under hashKey as a JSON object, or nil when the
+// hash is missing or empty.
+func (s *Store) HGetAll(ctx context.Context, hashKey string) (data []byte, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	bucket := s.hashes[hashKey]
+	fields := make(map[string]json.RawMessage, len(bucket))
+	for field, value := range bucket {
+		fields[field] = value
+	}
+	s.mu.RUnlock()
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("memstore: encode hash: %w", err)
+	}
+	return unwrapResult(encoded)
+}
+
+// GetStatus reports the sorted list of plain keys, mirroring /get_status.
+func (s *Store) GetStatus(ctx context.Context) (statusPayload []byte, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	s.mu.RUnlock()
+	sort.Strings(keys)
+	return json.Marshal(cstore.Status{Keys: keys})
+}
+
+// CompareAndSwap atomically replaces the value under key with new when the
+// payload Get would currently return equals old (nil meaning missing). A nil
+// new deletes the key.
+func (s *Store) CompareAndSwap(ctx context.Context, key string, old, new []byte) (swapped bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	value, err := normalizeSwapValue(new)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.values[key]
+	if match, err := matches(current, ok, old); err != nil || !match {
+		return false, err
+	}
+	if value == nil {
+		delete(s.values, key)
+		return true, nil
+	}
+	s.values[key] = value
+	return true, nil
+}
+
+// HCompareAndSwap is the hash-field counterpart of CompareAndSwap.
+func (s *Store) HCompareAndSwap(ctx context.Context, hashKey, field string, old, new []byte) (swapped bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	value, err := normalizeSwapValue(new)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.hashes[hashKey][field]
+	if match, err := matches(current, ok, old); err != nil || !match {
+		return false, err
+	}
+	bucket := s.hashes[hashKey]
+	if value == nil {
+		delete(bucket, field)
+		if len(bucket) == 0 {
+			delete(s.hashes, hashKey)
+		}
+		return true, nil
+	}
+	if bucket == nil {
+		bucket = make(map[string]json.RawMessage)
+		s.hashes[hashKey] = bucket
+	}
+	bucket[field] = value
+	return true, nil
+}
+
+func normalizeSwapValue(raw []byte) (json.RawMessage, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	return normalizeJSON(raw)
+}
+
+// matches compares a stored value against the payload a caller observed.
+func matches(current json.RawMessage, present bool, observed []byte) (bool, error) {
+	observed = bytes.TrimSpace(observed)
+	if len(observed) == 0 {
+		observed = []byte("null")
+	}
+	if !present {
+		return bytes.Equal(observed, []byte("null")), nil
+	}
+	visible, err := unwrapResult(current)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(bytes.TrimSpace(visible), observed), nil
+}
+
+type snapshot struct {
+	Values map[string]json.RawMessage            `json:"values"`
+	Hashes map[string]map[string]json.RawMessage `json:"hashes"`
+}
+
+// Snapshot writes the full store contents to w as JSON.
+func (s *Store) Snapshot(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := json.NewEncoder(w).Encode(snapshot{Values: s.values, Hashes: s.hashes}); err != nil {
+		return fmt.Errorf("memstore: write snapshot: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the store contents with a snapshot previously written by
+// Snapshot.
+func (s *Store) Restore(r io.Reader) error {
+	var snap snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return fmt.Errorf("memstore: read snapshot: %w", err)
+	}
+	if snap.Values == nil {
+		snap.Values = make(map[string]json.RawMessage)
+	}
+	if snap.Hashes == nil {
+		snap.Hashes = make(map[string]map[string]json.RawMessage)
+	}
+	s.mu.Lock()
+	s.values = snap.Values
+	s.hashes = snap.Hashes
+	s.mu.Unlock()
+	return nil
+}
+
+func normalizeJSON(raw []byte) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("memstore: value is required")
+	}
+	if !json.Valid(trimmed) {
+		return nil, fmt.Errorf("memstore: value is not valid JSON")
+	}
+	return append(json.RawMessage(nil), trimmed...), nil
+}
+
+// unwrapResult passes value through the same {"result": ...} envelope
+// decoding applied by the HTTP backend so JSON-string quirks match upstream.
+func unwrapResult(value json.RawMessage) ([]byte, error) {
+	envelope, err := json.Marshal(struct {
+		Result json.RawMessage `json:"result"`
+	}{Result: value})
+	if err != nil {
+		return nil, fmt.Errorf("memstore: encode result: %w", err)
+	}
+	return ratio1api.ExtractResult(envelope)
+}
@@ -0,0 +1,146 @@
+package memstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore"
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore/memstore"
+)
+
+type counter struct {
+	Count int `json:"count"`
+}
+
+func TestStoreRoundTrips(t *testing.T) {
+	client := memstore.NewClient()
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "jobs:2", counter{Count: 2}, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := client.Set(ctx, "jobs:1", counter{Count: 1}, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	var got counter
+	item, err := client.Get(ctx, "jobs:1", &got)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if item == nil || got.Count != 1 {
+		t.Fatalf("unexpected item: %#v value=%#v", item, got)
+	}
+
+	missing, err := client.Get(ctx, "missing", nil)
+	if err != nil || missing != nil {
+		t.Fatalf("expected nil for missing key, got %#v err=%v", missing, err)
+	}
+
+	if err := client.HSet(ctx, "jobs", "b", counter{Count: 20}, nil); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+	if err := client.HSet(ctx, "jobs", "a", counter{Count: 10}, nil); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+	hashMissing, err := client.HGet(ctx, "jobs", "zzz", nil)
+	if err != nil || hashMissing != nil {
+		t.Fatalf("expected nil for missing hash field, got %#v err=%v", hashMissing, err)
+	}
+	all, err := client.HGetAll(ctx, "jobs")
+	if err != nil {
+		t.Fatalf("HGetAll: %v", err)
+	}
+	if len(all) != 2 || all[0].Field != "a" || all[1].Field != "b" {
+		t.Fatalf("HGetAll returned unexpected items: %#v", all)
+	}
+	empty, err := client.HGetAll(ctx, "nothing")
+	if err != nil || empty != nil {
+		t.Fatalf("expected nil for missing hash, got %#v err=%v", empty, err)
+	}
+
+	status, err := client.GetStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetStatus: %v", err)
+	}
+	if status == nil || len(status.Keys) != 2 || status.Keys[0] != "jobs:1" || status.Keys[1] != "jobs:2" {
+		t.Fatalf("unexpected status: %#v", status)
+	}
+}
+
+func TestStoreUnwrapsJSONStrings(t *testing.T) {
+	client := memstore.NewClient()
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "encoded", `{"count":5}`, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	item, err := client.Get(ctx, "encoded", nil)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if item == nil || string(item.Value) != `{"count":5}` {
+		t.Fatalf("expected JSON string to be unwrapped, got %#v", item)
+	}
+
+	if err := client.Set(ctx, "plain", "alpha", nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	item, err = client.Get(ctx, "plain", nil)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if item == nil || string(item.Value) != `"alpha"` {
+		t.Fatalf("expected plain string to round-trip, got %#v", item)
+	}
+}
+
+func TestStoreRejectsInvalidJSON(t *testing.T) {
+	store := memstore.New()
+	if err := store.Set(context.Background(), "bad", []byte("{"), nil); err == nil {
+		t.Fatalf("expected error for invalid JSON")
+	}
+}
+
+func TestStoreConcurrentAccess(t *testing.T) {
+	client := cstore.NewWithBackend(memstore.New())
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("worker:%d", i)
+			for j := 0; j < 50; j++ {
+				if err := client.Set(ctx, key, counter{Count: j}, nil); err != nil {
+					t.Errorf("Set: %v", err)
+					return
+				}
+				if err := client.HSet(ctx, "workers", key, counter{Count: j}, nil); err != nil {
+					t.Errorf("HSet: %v", err)
+					return
+				}
+				if _, err := client.HGetAll(ctx, "workers"); err != nil {
+					t.Errorf("HGetAll: %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	all, err := client.HGetAll(ctx, "workers")
+	if err != nil {
+		t.Fatalf("HGetAll: %v", err)
+	}
+	if len(all) != 16 {
+		t.Fatalf("expected 16 fields, got %d", len(all))
+	}
+	var last counter
+	if err := json.Unmarshal(all[0].Value, &last); err != nil || last.Count != 49 {
+		t.Fatalf("unexpected final value: %#v err=%v", last, err)
+	}
+}
@@ -0,0 +1,89 @@
+package cstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PurgeExpired deletes expired values through the backend's Delete and HDel
+// operations so they stop occupying space in reads. Plain keys are discovered
+// through GetStatus; hash keys must be listed explicitly because the upstream
+// does not enumerate them. It returns the number of entries purged.
+func (c *Client) PurgeExpired(ctx context.Context, hashKeys ...string) (purged int, err error) {
+	if c == nil || c.backend == nil {
+		return 0, fmt.Errorf("cstore: client is nil")
+	}
+	now := time.Now()
+
+	status, err := c.GetStatus(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if status != nil {
+		for _, key := range status.Keys {
+			ok, err := purgeSlot(ctx, keySlot(c.backend, key), now)
+			if err != nil {
+				return purged, fmt.Errorf("cstore: purge key %q: %w", key, err)
+			}
+			if ok {
+				purged++
+			}
+		}
+	}
+
+	for _, hashKey := range hashKeys {
+		data, err := c.backend.HGetAll(ctx, hashKey)
+		if err != nil {
+			return purged, fmt.Errorf("cstore: purge hash %q: %w", hashKey, err)
+		}
+		if isNull(data) {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return purged, fmt.Errorf("cstore: decode hash %q: %w", hashKey, err)
+		}
+		names := make([]string, 0, len(fields))
+		for field, raw := range fields {
+			if _, meta := unwrapEnvelope(raw); meta.expired(now) {
+				names = append(names, field)
+			}
+		}
+		sort.Strings(names)
+		for _, field := range names {
+			ok, err := purgeSlot(ctx, hashSlot(c.backend, hashKey, field), now)
+			if err != nil {
+				return purged, fmt.Errorf("cstore: purge hash %q field %q: %w", hashKey, field, err)
+			}
+			if ok {
+				purged++
+			}
+		}
+	}
+	return purged, nil
+}
+
+// purgeSlot re-reads the entry and deletes it when still expired, using an
+// atomic swap where the backend supports one so fresh writes are not lost.
+func purgeSlot(ctx context.Context, s slot, now time.Time) (bool, error) {
+	current, err := s.get(ctx)
+	if err != nil {
+		return false, err
+	}
+	if isNull(current) {
+		return false, nil
+	}
+	if _, meta := unwrapEnvelope(current); !meta.expired(now) {
+		return false, nil
+	}
+	if s.cas != nil {
+		return s.cas(ctx, current, nil)
+	}
+	if err := s.del(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,114 @@
+package cstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore"
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore/memstore"
+)
+
+func TestTTLExpiresValues(t *testing.T) {
+	client := memstore.NewClient()
+	ctx := context.Background()
+	ttl := &cstore.SetOptions{TTL: 50 * time.Millisecond}
+
+	if err := client.Set(ctx, "session:1", counter{Count: 1}, ttl); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := client.Set(ctx, "session:keep", counter{Count: 2}, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := client.HSet(ctx, "leases", "a", counter{Count: 3}, ttl); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+	if err := client.HSet(ctx, "leases", "b", counter{Count: 4}, nil); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+
+	var got counter
+	item, err := client.Get(ctx, "session:1", &got)
+	if err != nil || item == nil || got.Count != 1 || item.ExpiresAt.IsZero() {
+		t.Fatalf("Get before expiry: %#v value=%#v err=%v", item, got, err)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+
+	if item, err := client.Get(ctx, "session:1", nil); err != nil || item != nil {
+		t.Fatalf("expected expired key to read as missing, got %#v err=%v", item, err)
+	}
+	if item, err := client.HGet(ctx, "leases", "a", nil); err != nil || item != nil {
+		t.Fatalf("expected expired field to read as missing, got %#v err=%v", item, err)
+	}
+	all, err := client.HGetAll(ctx, "leases")
+	if err != nil || len(all) != 1 || all[0].Field != "b" {
+		t.Fatalf("HGetAll after expiry: %#v err=%v", all, err)
+	}
+
+	purged, err := client.PurgeExpired(ctx, "leases")
+	if err != nil || purged != 2 {
+		t.Fatalf("PurgeExpired: purged=%d err=%v", purged, err)
+	}
+	purged, err = client.PurgeExpired(ctx, "leases")
+	if err != nil || purged != 0 {
+		t.Fatalf("second PurgeExpired: purged=%d err=%v", purged, err)
+	}
+	if item, err := client.Get(ctx, "session:keep", nil); err != nil || item == nil {
+		t.Fatalf("expected unexpired key to survive purge, got %#v err=%v", item, err)
+	}
+}
+
+func TestTTLWithVersionedWrites(t *testing.T) {
+	client := memstore.NewClient()
+	ctx := context.Background()
+
+	zero := uint64(0)
+	opts := &cstore.SetOptions{IfVersion: &zero, TTL: 30 * time.Millisecond}
+	if err := client.Set(ctx, "lock", "owner-a", opts); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := client.Set(ctx, "lock", "owner-b", opts); err == nil {
+		t.Fatalf("expected conflict while lock is held")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if err := client.Set(ctx, "lock", "owner-b", opts); err != nil {
+		t.Fatalf("expected expired lock to be acquirable: %v", err)
+	}
+	var owner string
+	if _, err := client.Get(ctx, "lock", &owner); err != nil || owner != "owner-b" {
+		t.Fatalf("Get: %q err=%v", owner, err)
+	}
+}
+
+func TestCompareAndSwapAndUpdateKeepExpiry(t *testing.T) {
+	client := memstore.NewClient()
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "lease", counter{Count: 1}, &cstore.SetOptions{TTL: time.Hour}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	before, err := client.Get(ctx, "lease", nil)
+	if err != nil || before == nil || before.ExpiresAt.IsZero() {
+		t.Fatalf("Get: %#v err=%v", before, err)
+	}
+
+	swapped, err := client.CompareAndSwap(ctx, "lease", counter{Count: 1}, counter{Count: 2})
+	if err != nil || !swapped {
+		t.Fatalf("CompareAndSwap: swapped=%v err=%v", swapped, err)
+	}
+	item, err := client.Get(ctx, "lease", nil)
+	if err != nil || item == nil || !item.ExpiresAt.Equal(before.ExpiresAt) {
+		t.Fatalf("CompareAndSwap changed the expiry: %#v err=%v", item, err)
+	}
+
+	err = client.Update(ctx, "lease", func(json.RawMessage) (any, error) { return counter{Count: 3}, nil })
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	item, err = client.Get(ctx, "lease", nil)
+	if err != nil || item == nil || !item.ExpiresAt.Equal(before.ExpiresAt) {
+		t.Fatalf("Update changed the expiry: %#v err=%v", item, err)
+	}
+}
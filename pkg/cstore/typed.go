@@ -0,0 +1,85 @@
+package cstore
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetAs retrieves key and decodes its value into T. Missing keys yield a nil
+// item, or ErrNotFound when c.StrictNotFound is set.
+func GetAs[T any](ctx context.Context, c *Client, key string) (*Item[T], error) {
+	item, err := getItem[T](ctx, c, key)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, c.notFound(fmt.Sprintf("key %q", key))
+	}
+	return item, nil
+}
+
+// SetAs stores value under key encoded as JSON.
+func SetAs[T any](ctx context.Context, c *Client, key string, value T, opts *SetOptions) error {
+	return setJSONEncoded(ctx, c, key, value, opts)
+}
+
+// HGetAs retrieves a hash field and decodes its value into T. Missing fields
+// yield a nil item, or ErrNotFound when c.StrictNotFound is set.
+func HGetAs[T any](ctx context.Context, c *Client, hashKey, field string) (*HashItem[T], error) {
+	item, err := getHashItem[T](ctx, c, hashKey, field)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, c.notFound(fmt.Sprintf("hash key %q field %q", hashKey, field))
+	}
+	return item, nil
+}
+
+// HSetAs stores value under hashKey/field encoded as JSON.
+func HSetAs[T any](ctx context.Context, c *Client, hashKey, field string, value T, opts *SetOptions) error {
+	return setHashJSONEncoded(ctx, c, hashKey, field, value, opts)
+}
+
+// HGetAllAs retrieves every field under hashKey decoded into T. Fields that fail
+// to decode are skipped; the returned error joins one *FieldDecodeError per
+// skipped field while items still holds every field that decoded cleanly.
+func HGetAllAs[T any](ctx context.Context, c *Client, hashKey string) ([]HashItem[T], error) {
+	return getAllHashItems[T](ctx, c, hashKey)
+}
+
+// Typed binds a Client to a single value type so callers get Item[T] and
+// HashItem[T] results without passing decode targets.
+type Typed[T any] struct {
+	client *Client
+}
+
+// NewTyped returns a Typed view over c.
+func NewTyped[T any](c *Client) *Typed[T] {
+	return &Typed[T]{client: c}
+}
+
+// Get retrieves key decoded into T. See GetAs.
+func (t *Typed[T]) Get(ctx context.Context, key string) (*Item[T], error) {
+	return GetAs[T](ctx, t.client, key)
+}
+
+// Set stores value under key. See SetAs.
+func (t *Typed[T]) Set(ctx context.Context, key string, value T, opts *SetOptions) error {
+	return SetAs(ctx, t.client, key, value, opts)
+}
+
+// HGet retrieves hashKey/field decoded into T. See HGetAs.
+func (t *Typed[T]) HGet(ctx context.Context, hashKey, field string) (*HashItem[T], error) {
+	return HGetAs[T](ctx, t.client, hashKey, field)
+}
+
+// HSet stores value under hashKey/field. See HSetAs.
+func (t *Typed[T]) HSet(ctx context.Context, hashKey, field string, value T, opts *SetOptions) error {
+	return HSetAs(ctx, t.client, hashKey, field, value, opts)
+}
+
+// HGetAll retrieves every field under hashKey decoded into T. See HGetAllAs.
+func (t *Typed[T]) HGetAll(ctx context.Context, hashKey string) ([]HashItem[T], error) {
+	return HGetAllAs[T](ctx, t.client, hashKey)
+}
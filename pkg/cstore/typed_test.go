@@ -0,0 +1,60 @@
+package cstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore"
+	"github.com/Ratio1/edge_sdk_go/pkg/cstore/memstore"
+)
+
+func TestTypedRoundTrips(t *testing.T) {
+	client := memstore.NewClient()
+	ctx := context.Background()
+	typed := cstore.NewTyped[counter](client)
+
+	if err := typed.Set(ctx, "jobs:1", counter{Count: 4}, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	item, err := typed.Get(ctx, "jobs:1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if item == nil || item.Key != "jobs:1" || item.Value.Count != 4 {
+		t.Fatalf("unexpected item: %#v", item)
+	}
+	missing, err := cstore.GetAs[counter](ctx, client, "missing")
+	if err != nil || missing != nil {
+		t.Fatalf("expected nil for missing key, got %#v err=%v", missing, err)
+	}
+
+	if err := cstore.HSetAs(ctx, client, "jobs", "a", counter{Count: 1}, nil); err != nil {
+		t.Fatalf("HSetAs: %v", err)
+	}
+	hashItem, err := typed.HGet(ctx, "jobs", "a")
+	if err != nil || hashItem == nil || hashItem.Value.Count != 1 {
+		t.Fatalf("HGet: %#v err=%v", hashItem, err)
+	}
+}
+
+func TestHGetAllAsReportsFieldErrors(t *testing.T) {
+	client := memstore.NewClient()
+	ctx := context.Background()
+
+	if err := client.HSet(ctx, "jobs", "good", counter{Count: 1}, nil); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+	if err := client.HSet(ctx, "jobs", "bad", []int{1, 2}, nil); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+
+	items, err := cstore.HGetAllAs[counter](ctx, client, "jobs")
+	if len(items) != 1 || items[0].Field != "good" || items[0].Value.Count != 1 {
+		t.Fatalf("expected the decodable field to be returned, got %#v", items)
+	}
+	var fieldErr *cstore.FieldDecodeError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "bad" || fieldErr.HashKey != "jobs" {
+		t.Fatalf("expected FieldDecodeError for field bad, got %v", err)
+	}
+}
@@ -1,11 +1,20 @@
 package cstore
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // Item represents a stored key/value pair.
 type Item[T any] struct {
 	Key   string
 	Value T
+	// Version is the optimistic concurrency version recorded by versioned
+	// writes. It is zero for values written without SetOptions.IfVersion.
+	Version uint64
+	// ExpiresAt is the expiry recorded by SetOptions.TTL, or the zero time.
+	ExpiresAt time.Time
 }
 
 // Status describes the payload returned by /get_status.
@@ -13,17 +22,86 @@ type Status struct {
 	Keys []string `json:"keys"`
 }
 
+// KeysOptions filters and pages the key listing returned by Client.Keys.
+type KeysOptions struct {
+	// Prefix keeps only keys starting with the given string.
+	Prefix string
+	// Pattern keeps only keys matching a glob where '*' matches any run of
+	// characters, '?' matches one character and '[...]' matches a class.
+	// '\' escapes the next character, inside a class as well.
+	Pattern string
+	// Limit caps the number of keys per page. Zero returns every match.
+	Limit int
+	// Cursor resumes a listing from KeysPage.NextCursor.
+	Cursor string
+	// IncludeDeleted keeps keys that Get reports as missing: over HTTP, keys
+	// removed with Delete (which leaves a null tombstone) and keys whose TTL
+	// has expired. Leaving it unset costs one Get per candidate key.
+	IncludeDeleted bool
+}
+
+// KeysPage is a sorted page of keys returned by Client.Keys.
+type KeysPage struct {
+	Keys []string
+	// NextCursor is empty once the listing is exhausted.
+	NextCursor string
+}
+
 // HashItem represents a field stored under a hash key.
 type HashItem[T any] struct {
 	HashKey string
 	Field   string
 	Value   T
+	// Version mirrors Item.Version for hash fields.
+	Version uint64
+	// ExpiresAt mirrors Item.ExpiresAt for hash fields.
+	ExpiresAt time.Time
+}
+
+// SetOptions carries optional write controls for Set and HSet.
+type SetOptions struct {
+	// IfVersion makes the write conditional on the stored version matching
+	// *IfVersion; zero matches a missing or unversioned value. Successful
+	// conditional writes store the value inside a small envelope carrying the
+	// incremented version, which Get, HGet and HGetAll unwrap transparently.
+	// A mismatch fails with ErrConflict.
+	//
+	// The check is atomic only on backends implementing
+	// CompareAndSwapBackend, such as memstore. The HTTP backend has no
+	// conditional writes: it writes and reads the value back, which catches
+	// most conflicting writers, but a writer that interleaves with the
+	// read-back can still be overwritten without an error.
+	IfVersion *uint64
+
+	// TTL expires the value after the given duration. The upstream REST API
+	// has no expiry support, so the deadline is stored in the same envelope
+	// and expired values are filtered out by Get, HGet and HGetAll until
+	// Client.PurgeExpired removes them. Zero disables expiry.
+	TTL time.Duration
 }
 
-// SetOptions is reserved for future write controls.
-type SetOptions struct{}
+// FieldDecodeError reports a hash field whose value could not be decoded.
+type FieldDecodeError struct {
+	HashKey string
+	Field   string
+	Err     error
+}
+
+func (e *FieldDecodeError) Error() string {
+	return fmt.Sprintf("cstore: decode hash field %q of %q: %v", e.Field, e.HashKey, e.Err)
+}
+
+func (e *FieldDecodeError) Unwrap() error {
+	return e.Err
+}
 
 var (
-	// ErrNotFound is returned when a key is missing.
+	// ErrNotFound is returned when a key is missing and Client.StrictNotFound
+	// is enabled, including for HTTP 404 responses and the upstream "error"
+	// result string.
 	ErrNotFound = errors.New("cstore: not found")
+
+	// ErrConflict is returned when a conditional write loses against a
+	// concurrent writer or the stored version does not match.
+	ErrConflict = errors.New("cstore: version conflict")
 )
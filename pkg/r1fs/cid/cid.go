@@ -0,0 +1,193 @@
+// Package cid computes IPFS content identifiers locally, using the same
+// chunking and UnixFS layout as the IPFS daemon behind an R1FS node.
+//
+// Sum hashes a byte stream the way `ipfs add` does with default settings:
+// 256 KiB chunks arranged in a balanced DAG of up to 174 links per node.
+// Version 0 produces dag-pb leaves and base58 "Qm..." identifiers; version 1
+// follows `ipfs add --cid-version=1`, which stores leaves as raw blocks and
+// renders identifiers in base32 ("bafy..."/"bafk...").
+//
+// Files uploaded with a secret are encrypted by the node before they are
+// added, so their CIDs cannot be predicted from the plaintext.
+package cid
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Multicodec identifiers used by R1FS content.
+const (
+	CodecRaw   uint64 = 0x55
+	CodecDagPB uint64 = 0x70
+)
+
+const (
+	// multihash code and digest length for sha2-256.
+	sha256Code = 0x12
+	sha256Len  = 32
+)
+
+// CID identifies a block by its sha2-256 digest.
+type CID struct {
+	Version int
+	Codec   uint64
+	Digest  []byte
+}
+
+var base32Lower = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// String renders c in its canonical text form: base58btc for version 0 and
+// multibase base32 for version 1.
+func (c CID) String() string {
+	if c.Version == 0 {
+		return base58Encode(c.multihash())
+	}
+	return "b" + base32Lower.EncodeToString(c.Bytes())
+}
+
+// Bytes returns the binary form of c, as embedded in dag-pb links.
+func (c CID) Bytes() []byte {
+	if c.Version == 0 {
+		return c.multihash()
+	}
+	buf := binary.AppendUvarint(nil, 1)
+	buf = binary.AppendUvarint(buf, c.Codec)
+	return append(buf, c.multihash()...)
+}
+
+// Equal reports whether c and other identify the same block.
+func (c CID) Equal(other CID) bool {
+	return c.Version == other.Version && c.Codec == other.Codec && bytes.Equal(c.Digest, other.Digest)
+}
+
+// Defined reports whether c holds a digest.
+func (c CID) Defined() bool {
+	return len(c.Digest) > 0
+}
+
+func (c CID) multihash() []byte {
+	return append([]byte{sha256Code, sha256Len}, c.Digest...)
+}
+
+// NewV0 returns the version 0 CID of a dag-pb block with the given sha2-256 digest.
+func NewV0(digest [sha256Len]byte) CID {
+	return CID{Version: 0, Codec: CodecDagPB, Digest: digest[:]}
+}
+
+func newCID(version int, codec uint64, block []byte) CID {
+	sum := sha256.Sum256(block)
+	if version == 0 {
+		return NewV0(sum)
+	}
+	return CID{Version: 1, Codec: codec, Digest: sum[:]}
+}
+
+// Parse decodes a CIDv0 ("Qm...") or a base32 CIDv1 ("b...") string. Only
+// sha2-256 multihashes are supported.
+func Parse(s string) (CID, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case len(s) == 46 && strings.HasPrefix(s, "Qm"):
+		raw, err := base58Decode(s)
+		if err != nil {
+			return CID{}, fmt.Errorf("cid: parse %q: %w", s, err)
+		}
+		digest, err := parseMultihash(raw)
+		if err != nil {
+			return CID{}, fmt.Errorf("cid: parse %q: %w", s, err)
+		}
+		return CID{Version: 0, Codec: CodecDagPB, Digest: digest}, nil
+	case strings.HasPrefix(s, "b"):
+		raw, err := base32Lower.DecodeString(s[1:])
+		if err != nil {
+			return CID{}, fmt.Errorf("cid: parse %q: %w", s, err)
+		}
+		version, n := binary.Uvarint(raw)
+		if n <= 0 || version != 1 {
+			return CID{}, fmt.Errorf("cid: parse %q: unsupported version", s)
+		}
+		raw = raw[n:]
+		codec, n := binary.Uvarint(raw)
+		if n <= 0 {
+			return CID{}, fmt.Errorf("cid: parse %q: invalid codec", s)
+		}
+		digest, err := parseMultihash(raw[n:])
+		if err != nil {
+			return CID{}, fmt.Errorf("cid: parse %q: %w", s, err)
+		}
+		return CID{Version: 1, Codec: codec, Digest: digest}, nil
+	default:
+		return CID{}, fmt.Errorf("cid: parse %q: unsupported encoding", s)
+	}
+}
+
+func parseMultihash(raw []byte) ([]byte, error) {
+	if len(raw) != 2+sha256Len || raw[0] != sha256Code || raw[1] != sha256Len {
+		return nil, errors.New("only sha2-256 multihashes are supported")
+	}
+	return append([]byte(nil), raw[2:]...), nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func base58Encode(input []byte) string {
+	zeros := 0
+	for zeros < len(input) && input[zeros] == 0 {
+		zeros++
+	}
+	digits := make([]byte, 0, len(input)*138/100+1)
+	for _, b := range input[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	out := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		out[i] = base58Alphabet[0]
+	}
+	for i, d := range digits {
+		out[len(out)-1-i] = base58Alphabet[d]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	bytesLE := make([]byte, 0, len(s)*733/1000+1)
+	for i := zeros; i < len(s); i++ {
+		carry := strings.IndexByte(base58Alphabet, s[i])
+		if carry < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", s[i])
+		}
+		for j := range bytesLE {
+			carry += int(bytesLE[j]) * 58
+			bytesLE[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytesLE = append(bytesLE, byte(carry))
+			carry >>= 8
+		}
+	}
+	out := make([]byte, zeros+len(bytesLE))
+	for i, b := range bytesLE {
+		out[len(out)-1-i] = b
+	}
+	return out, nil
+}
@@ -0,0 +1,263 @@
+package cid_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/cid"
+)
+
+func TestSumMatchesIPFSAdd(t *testing.T) {
+	// Reference values produced by `ipfs add` and `ipfs add --cid-version=1`.
+	cases := []struct {
+		data string
+		v0   string
+		v1   string
+	}{
+		{"", "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH", "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"},
+		{"hello world\n", "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o", "bafkreifjjcie6lypi6ny7amxnfftagclbuxndqonfipmb64f2km2devei4"},
+		{"hello world", "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD", "bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e"},
+	}
+	for _, tc := range cases {
+		v0, err := cid.SumBytes([]byte(tc.data), nil)
+		if err != nil {
+			t.Fatalf("SumBytes v0: %v", err)
+		}
+		if v0.String() != tc.v0 {
+			t.Fatalf("v0 of %q = %s, want %s", tc.data, v0, tc.v0)
+		}
+		v1, err := cid.SumBytes([]byte(tc.data), &cid.Options{Version: 1})
+		if err != nil {
+			t.Fatalf("SumBytes v1: %v", err)
+		}
+		if v1.String() != tc.v1 {
+			t.Fatalf("v1 of %q = %s, want %s", tc.data, v1, tc.v1)
+		}
+	}
+}
+
+func TestSumStreamsChunkedContent(t *testing.T) {
+	// 175 chunks of 4 bytes need two levels of internal nodes.
+	data := bytes.Repeat([]byte("r1fs"), 175)
+	opts := &cid.Options{ChunkSize: 4}
+	want, err := cid.SumBytes(data, opts)
+	if err != nil {
+		t.Fatalf("SumBytes: %v", err)
+	}
+	got, err := cid.Sum(iotest.OneByteReader(bytes.NewReader(data)), opts)
+	if err != nil {
+		t.Fatalf("Sum: %v", err)
+	}
+	if !got.Equal(want) {
+		t.Fatalf("streamed CID %s differs from %s", got, want)
+	}
+	single, err := cid.SumBytes(data, nil)
+	if err != nil {
+		t.Fatalf("SumBytes: %v", err)
+	}
+	if single.Equal(want) {
+		t.Fatalf("chunk size should change the DAG layout")
+	}
+
+	v1, err := cid.SumBytes(data, &cid.Options{Version: 1, ChunkSize: 4})
+	if err != nil {
+		t.Fatalf("SumBytes v1: %v", err)
+	}
+	if v1.Codec != cid.CodecDagPB || !strings.HasPrefix(v1.String(), "bafybei") {
+		t.Fatalf("multi-chunk v1 root should be dag-pb, got %s", v1)
+	}
+}
+
+// pbBytes and pbVarint hand-encode protobuf fields for the layout tests.
+func pbBytes(buf []byte, field int, v []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field)<<3|2)
+	buf = binary.AppendUvarint(buf, uint64(len(v)))
+	return append(buf, v...)
+}
+
+func pbVarint(buf []byte, field int, v uint64) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field)<<3)
+	return binary.AppendUvarint(buf, v)
+}
+
+func TestSumEncodesLaterLeavesAsRaw(t *testing.T) {
+	// Kubo's balanced importer emits the first leaf as UnixFS File (2) and
+	// the following ones as Raw (0); the root lists both with block sizes.
+	leaf := func(kind uint64, data string) []byte {
+		unixfs := pbVarint(nil, 1, kind)
+		unixfs = pbBytes(unixfs, 2, []byte(data))
+		unixfs = pbVarint(unixfs, 3, uint64(len(data)))
+		return pbBytes(nil, 1, unixfs)
+	}
+	leaves := [][]byte{leaf(2, "r1fs"), leaf(0, "edge")}
+	var root []byte
+	for _, block := range leaves {
+		digest := sha256.Sum256(block)
+		link := pbBytes(nil, 1, cid.NewV0(digest).Bytes())
+		link = pbBytes(link, 2, nil)
+		link = pbVarint(link, 3, uint64(len(block)))
+		root = pbBytes(root, 2, link)
+	}
+	unixfs := pbVarint(nil, 1, 2)
+	unixfs = pbVarint(unixfs, 3, 8)
+	unixfs = pbVarint(unixfs, 4, 4)
+	unixfs = pbVarint(unixfs, 4, 4)
+	root = pbBytes(root, 1, unixfs)
+	want := cid.NewV0(sha256.Sum256(root))
+
+	got, err := cid.SumBytes([]byte("r1fsedge"), &cid.Options{ChunkSize: 4})
+	if err != nil {
+		t.Fatalf("SumBytes: %v", err)
+	}
+	if !got.Equal(want) {
+		t.Fatalf("SumBytes = %s, want %s", got, want)
+	}
+}
+
+func TestSumMatchesIPFSAddForChunkedFiles(t *testing.T) {
+	ipfs, err := exec.LookPath("ipfs")
+	if err != nil {
+		t.Skip("ipfs not installed")
+	}
+	sizes := []int{
+		cid.DefaultChunkSize + 1000,                      // two leaves
+		(cid.DefaultMaxLinks+1)*cid.DefaultChunkSize + 1, // two levels of internal nodes
+	}
+	for _, size := range sizes {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i*7 + i/251)
+		}
+		cmd := exec.Command(ipfs, "add", "--only-hash", "--quiet")
+		cmd.Stdin = bytes.NewReader(data)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Skipf("ipfs add failed: %v", err)
+		}
+		got, err := cid.SumBytes(data, nil)
+		if err != nil {
+			t.Fatalf("SumBytes: %v", err)
+		}
+		if want := strings.TrimSpace(string(out)); got.String() != want {
+			t.Fatalf("%d bytes: SumBytes = %s, ipfs add = %s", size, got, want)
+		}
+	}
+}
+
+func TestSumWrapName(t *testing.T) {
+	plain, err := cid.SumBytes([]byte("hello world\n"), nil)
+	if err != nil {
+		t.Fatalf("SumBytes: %v", err)
+	}
+	wrapped, err := cid.SumBytes([]byte("hello world\n"), &cid.Options{WrapName: "hello.txt"})
+	if err != nil {
+		t.Fatalf("SumBytes: %v", err)
+	}
+	renamed, err := cid.SumBytes([]byte("hello world\n"), &cid.Options{WrapName: "other.txt"})
+	if err != nil {
+		t.Fatalf("SumBytes: %v", err)
+	}
+	if wrapped.Equal(plain) || wrapped.Equal(renamed) {
+		t.Fatalf("directory CID should depend on the entry name")
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o",
+		"bafkreifjjcie6lypi6ny7amxnfftagclbuxndqonfipmb64f2km2devei4",
+	} {
+		c, err := cid.Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", s, err)
+		}
+		if c.String() != s {
+			t.Fatalf("round trip %s -> %s", s, c)
+		}
+	}
+	if _, err := cid.Parse("not-a-cid"); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestMarshalJSONMatchesPython(t *testing.T) {
+	got, err := cid.MarshalJSON(map[string]any{"b": []int{1, 2}, "a": "é😀", "c": map[string]string{"k": "a,b: c"}})
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	// json.dumps({"a": "é😀", "b": [1, 2], "c": {"k": "a,b: c"}})
+	want := `{"a": "\u00e9\ud83d\ude00", "b": [1, 2], "c": {"k": "a,b: c"}}`
+	if string(got) != want {
+		t.Fatalf("MarshalJSON = %s, want %s", got, want)
+	}
+}
+
+// TestSumJSONMatchesUpstream cross-checks SumJSON against a live node's
+// calculate_json_cid endpoint when EE_R1FS_API_URL is set.
+func TestSumJSONMatchesUpstream(t *testing.T) {
+	if os.Getenv("EE_R1FS_API_URL") == "" {
+		t.Skip("EE_R1FS_API_URL not set")
+	}
+	client, err := r1fs.NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv: %v", err)
+	}
+	doc := map[string]any{"service": "r1fs", "enabled": true, "replicas": 3}
+	remote, err := client.CalculateJSONCID(context.Background(), doc, 0, nil)
+	if err != nil {
+		t.Fatalf("CalculateJSONCID: %v", err)
+	}
+	local, err := cid.SumJSON(doc, nil)
+	if err != nil {
+		t.Fatalf("SumJSON: %v", err)
+	}
+	if local.String() != remote {
+		t.Fatalf("local CID %s, upstream %s", local, remote)
+	}
+
+	data := []byte("hello world\n")
+	uploaded, err := client.AddFile(context.Background(), bytes.NewReader(data), &r1fs.DataOptions{Filename: "hello.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	plain, _ := cid.SumBytes(data, nil)
+	wrapped, _ := cid.SumBytes(data, &cid.Options{WrapName: "hello.txt"})
+	if uploaded != plain.String() && uploaded != wrapped.String() {
+		t.Fatalf("uploaded CID %s matches neither %s nor %s", uploaded, plain, wrapped)
+	}
+}
+
+// TestSumPickleMatchesUpstream cross-checks SumPickle against a live node's
+// calculate_pickle_cid endpoint when EE_R1FS_API_URL is set. Without a secret
+// the nonce must not change the CID.
+func TestSumPickleMatchesUpstream(t *testing.T) {
+	if os.Getenv("EE_R1FS_API_URL") == "" {
+		t.Skip("EE_R1FS_API_URL not set")
+	}
+	client, err := r1fs.NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv: %v", err)
+	}
+	doc := map[string]any{"service": "r1fs", "scores": []any{1, 2.5, "x"}, "nested": map[string]any{"ok": true, "none": nil}}
+	local, err := cid.SumPickle(doc, nil)
+	if err != nil {
+		t.Fatalf("SumPickle: %v", err)
+	}
+	for _, nonce := range []int{0, 7} {
+		remote, err := client.CalculatePickleCID(context.Background(), doc, nonce, nil)
+		if err != nil {
+			t.Fatalf("CalculatePickleCID: %v", err)
+		}
+		if local.String() != remote {
+			t.Fatalf("nonce %d: local CID %s, upstream %s", nonce, local, remote)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package cid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/pickle"
+)
+
+// MarshalJSON encodes v the way the node's Python runtime serializes JSON
+// documents with json.dumps defaults: ", " and ": " separators and non-ASCII
+// characters escaped as \uXXXX. Go maps are encoded with sorted keys, so use
+// structs when the document relies on a specific key order.
+func MarshalJSON(v any) ([]byte, error) {
+	var compact bytes.Buffer
+	enc := json.NewEncoder(&compact)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("cid: encode JSON: %w", err)
+	}
+	src := bytes.TrimRight(compact.Bytes(), "\n")
+
+	out := make([]byte, 0, len(src)+len(src)/8)
+	inString, escaped := false, false
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case inString && c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(src[i:])
+			if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+				out = fmt.Appendf(out, `\u%04x\u%04x`, r1, r2)
+			} else {
+				out = fmt.Appendf(out, `\u%04x`, r)
+			}
+			i += size
+			escaped = false
+			continue
+		case inString:
+			out = append(out, c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == ',' || c == ':':
+			out = append(out, c, ' ')
+		default:
+			out = append(out, c)
+		}
+		i++
+	}
+	return out, nil
+}
+
+// SumJSON returns the CID of v serialized with MarshalJSON.
+func SumJSON(v any, opts *Options) (CID, error) {
+	data, err := MarshalJSON(v)
+	if err != nil {
+		return CID{}, err
+	}
+	return SumBytes(data, opts)
+}
+
+// SumPickle returns the CID of the pickle an R1FS node stores when v is sent
+// to /add_pickle: v travels as JSON and the node pickles the decoded document.
+// It matches r1fs.Client.CalculatePickleCID for uploads without a secret,
+// whatever nonce is passed there.
+func SumPickle(v any, opts *Options) (CID, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return CID{}, fmt.Errorf("cid: encode JSON: %w", err)
+	}
+	pickled, err := pickle.MarshalJSON(data)
+	if err != nil {
+		return CID{}, fmt.Errorf("cid: %w", err)
+	}
+	return SumBytes(pickled, opts)
+}
@@ -0,0 +1,261 @@
+package cid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// DefaultChunkSize matches the size-262144 chunker used by `ipfs add`.
+	DefaultChunkSize = 256 * 1024
+	// DefaultMaxLinks is the fan-out of the balanced DAG built by `ipfs add`.
+	DefaultMaxLinks = 174
+)
+
+// UnixFS node types.
+const (
+	unixfsRaw       = 0
+	unixfsDirectory = 1
+	unixfsFile      = 2
+)
+
+// Options tune how content is laid out before hashing. The zero value
+// reproduces `ipfs add` with default settings.
+type Options struct {
+	// Version selects CIDv0 (default) or CIDv1 with raw leaves.
+	Version int
+	// ChunkSize overrides DefaultChunkSize.
+	ChunkSize int
+	// WrapName wraps the file in a directory entry with this name, as
+	// `ipfs add --wrap-with-directory` does, and returns the directory CID.
+	WrapName string
+}
+
+func (o *Options) normalize() (Options, error) {
+	var opts Options
+	if o != nil {
+		opts = *o
+	}
+	if opts.Version != 0 && opts.Version != 1 {
+		return opts, fmt.Errorf("cid: unsupported version %d", opts.Version)
+	}
+	if opts.ChunkSize < 0 {
+		return opts, errors.New("cid: chunk size must not be negative")
+	}
+	if opts.ChunkSize == 0 {
+		opts.ChunkSize = DefaultChunkSize
+	}
+	return opts, nil
+}
+
+// SumBytes returns the CID `ipfs add` would assign to data.
+func SumBytes(data []byte, opts *Options) (CID, error) {
+	return Sum(bytes.NewReader(data), opts)
+}
+
+// Sum returns the CID `ipfs add` would assign to the content of r. The input
+// is processed one chunk at a time, so arbitrarily large streams can be hashed
+// in constant memory.
+func Sum(r io.Reader, opts *Options) (CID, error) {
+	h, err := NewHasher(opts)
+	if err != nil {
+		return CID{}, err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return CID{}, fmt.Errorf("cid: read content: %w", err)
+	}
+	return h.Sum(), nil
+}
+
+// Hasher computes a CID incrementally from the bytes written to it. It is
+// useful to hash content while it is being copied elsewhere.
+type Hasher struct {
+	opts    Options
+	builder dagBuilder
+	pending []byte
+	leaves  int
+}
+
+// NewHasher returns a Hasher laying content out according to opts.
+func NewHasher(opts *Options) (*Hasher, error) {
+	o, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+	return &Hasher{
+		opts:    o,
+		builder: dagBuilder{version: o.Version},
+		pending: make([]byte, 0, o.ChunkSize),
+	}, nil
+}
+
+// Write adds p to the content being hashed. It never returns an error.
+func (h *Hasher) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		n := copy(h.pending[len(h.pending):cap(h.pending)], p)
+		h.pending = h.pending[:len(h.pending)+n]
+		p = p[n:]
+		if len(h.pending) == cap(h.pending) {
+			h.flush()
+		}
+	}
+	return written, nil
+}
+
+func (h *Hasher) flush() {
+	h.builder.addLeaf(h.pending)
+	h.pending = h.pending[:0]
+	h.leaves++
+}
+
+// Sum returns the CID of the content written so far. The Hasher must not be
+// written to afterwards.
+func (h *Hasher) Sum() CID {
+	if len(h.pending) > 0 || h.leaves == 0 {
+		h.flush()
+	}
+	root := h.builder.finish()
+	if h.opts.WrapName != "" {
+		root = wrapDirectory(h.opts.Version, h.opts.WrapName, root)
+	}
+	return root.cid
+}
+
+// dagLink is a child reference inside a dag-pb node.
+type dagLink struct {
+	cid      CID
+	tsize    uint64 // serialized size of the child DAG
+	fileSize uint64 // content bytes below the child
+}
+
+// dagBuilder assembles the balanced layout bottom-up. levels[i] collects the
+// not yet grouped nodes of depth i; a level is folded into a parent as soon as
+// it holds DefaultMaxLinks entries.
+type dagBuilder struct {
+	version int
+	levels  [][]dagLink
+	leaves  int
+}
+
+// addLeaf appends the next chunk. Like the balanced importer in Kubo, the
+// first CIDv0 leaf is a UnixFS File node and every later one is a Raw node.
+func (b *dagBuilder) addLeaf(data []byte) {
+	var link dagLink
+	if b.version == 1 {
+		link = dagLink{cid: newCID(1, CodecRaw, data), tsize: uint64(len(data)), fileSize: uint64(len(data))}
+	} else {
+		kind := uint64(unixfsFile)
+		if b.leaves > 0 {
+			kind = unixfsRaw
+		}
+		block := encodeNode(nil, encodeUnixFS(kind, data, uint64(len(data)), nil))
+		link = dagLink{cid: newCID(0, CodecDagPB, block), tsize: uint64(len(block)), fileSize: uint64(len(data))}
+	}
+	b.leaves++
+	b.push(0, link)
+}
+
+func (b *dagBuilder) push(level int, link dagLink) {
+	if level == len(b.levels) {
+		b.levels = append(b.levels, nil)
+	}
+	b.levels[level] = append(b.levels[level], link)
+	if len(b.levels[level]) == DefaultMaxLinks {
+		parent := b.parent(b.levels[level])
+		b.levels[level] = nil
+		b.push(level+1, parent)
+	}
+}
+
+func (b *dagBuilder) finish() dagLink {
+	for level := 0; ; level++ {
+		links := b.levels[level]
+		top := level == len(b.levels)-1
+		if top && len(links) == 1 {
+			return links[0]
+		}
+		if len(links) == 0 {
+			continue
+		}
+		b.levels[level] = nil
+		b.push(level+1, b.parent(links))
+	}
+}
+
+func (b *dagBuilder) parent(children []dagLink) dagLink {
+	var fileSize, tsize uint64
+	blockSizes := make([]uint64, len(children))
+	for i, child := range children {
+		fileSize += child.fileSize
+		tsize += child.tsize
+		blockSizes[i] = child.fileSize
+	}
+	named := make([]namedLink, len(children))
+	for i, child := range children {
+		named[i] = namedLink{dagLink: child}
+	}
+	block := encodeNode(named, encodeUnixFS(unixfsFile, nil, fileSize, blockSizes))
+	return dagLink{
+		cid:      newCID(b.version, CodecDagPB, block),
+		tsize:    tsize + uint64(len(block)),
+		fileSize: fileSize,
+	}
+}
+
+func wrapDirectory(version int, name string, child dagLink) dagLink {
+	block := encodeNode([]namedLink{{dagLink: child, name: name}}, encodeUnixFS(unixfsDirectory, nil, 0, nil))
+	return dagLink{
+		cid:      newCID(version, CodecDagPB, block),
+		tsize:    child.tsize + uint64(len(block)),
+		fileSize: child.fileSize,
+	}
+}
+
+type namedLink struct {
+	dagLink
+	name string
+}
+
+// encodeNode serializes a dag-pb PBNode: links (field 2) precede data (field 1)
+// as required by the canonical encoding.
+func encodeNode(links []namedLink, data []byte) []byte {
+	var buf []byte
+	for _, link := range links {
+		var pbLink []byte
+		pbLink = appendBytesField(pbLink, 1, link.cid.Bytes())
+		pbLink = appendBytesField(pbLink, 2, []byte(link.name))
+		pbLink = appendVarintField(pbLink, 3, link.tsize)
+		buf = appendBytesField(buf, 2, pbLink)
+	}
+	return appendBytesField(buf, 1, data)
+}
+
+// encodeUnixFS serializes the UnixFS Data message carried in a dag-pb node.
+func encodeUnixFS(kind uint64, data []byte, fileSize uint64, blockSizes []uint64) []byte {
+	buf := appendVarintField(nil, 1, kind)
+	if len(data) > 0 {
+		buf = appendBytesField(buf, 2, data)
+	}
+	if kind == unixfsFile || kind == unixfsRaw {
+		buf = appendVarintField(buf, 3, fileSize)
+	}
+	for _, size := range blockSizes {
+		buf = appendVarintField(buf, 4, size)
+	}
+	return buf
+}
+
+func appendVarintField(buf []byte, field int, v uint64) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field)<<3)
+	return binary.AppendUvarint(buf, v)
+}
+
+func appendBytesField(buf []byte, field int, v []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field)<<3|2)
+	buf = binary.AppendUvarint(buf, uint64(len(v)))
+	return append(buf, v...)
+}
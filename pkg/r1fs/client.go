@@ -5,9 +5,9 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -15,15 +15,36 @@ import (
 
 	"github.com/Ratio1/edge_sdk_go/internal/httpx"
 	"github.com/Ratio1/edge_sdk_go/internal/ratio1api"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/pickle"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1transport"
 )
 
 // Client provides HTTP access to the R1FS manager API.
 type Client struct {
 	backend Backend
+
+	// VerifyContent re-hashes downloaded files and reports an error matching
+	// ErrIntegrity when they do not match the requested CID. Content fetched
+	// with a secret is encrypted on the node, so it cannot be verified and is
+	// rejected while this is enabled.
+	VerifyContent bool
+
+	// AllowLocalReads lets Open and GetFileTo read the path reported by
+	// /get_file from this host even when the base URL is not a loopback
+	// address or unix socket. The file is only used when its content matches
+	// the requested CID.
+	AllowLocalReads bool
+
+	// StrictNotFound makes lookups report an error wrapping ErrNotFound when
+	// the node answers with HTTP 404 or the "error" result string, the same
+	// way cstore.Client.StrictNotFound classifies missing keys. Without it
+	// those failures are returned as they arrive. Backends such as memfs may
+	// report ErrNotFound themselves either way.
+	StrictNotFound bool
 }
 
 // New constructs an HTTP-backed client.
-func New(baseURL string, opts ...httpx.Option) (client *Client, err error) {
+func New(baseURL string, opts ...r1transport.Option) (client *Client, err error) {
 	cl, err := httpx.NewClient(baseURL, opts...)
 	if err != nil {
 		return nil, err
@@ -41,6 +62,15 @@ func NewWithBackend(b Backend) *Client {
 	return &Client{backend: b}
 }
 
+// Backend returns the backend the client issues requests to, for wrapping
+// with NewEncryptingBackend and similar decorators.
+func (c *Client) Backend() Backend {
+	if c == nil {
+		return nil
+	}
+	return c.backend
+}
+
 // AddFileBase64 writes data via /add_file_base64 and returns the upstream CID.
 func (c *Client) AddFileBase64(ctx context.Context, data io.Reader, opts *DataOptions) (cid string, err error) {
 	if c == nil || c.backend == nil {
@@ -54,18 +84,42 @@ func (c *Client) AddFileBase64(ctx context.Context, data io.Reader, opts *DataOp
 }
 
 // AddFile uploads data using the /add_file endpoint (multipart form upload) and returns the upstream CID.
+// The payload is streamed when the backend supports it. Seekable readers are
+// rewound for retries; other readers are sent once, so use AddFileFrom when a
+// non-seekable source should be retried.
 func (c *Client) AddFile(ctx context.Context, data io.Reader, opts *DataOptions) (cid string, err error) {
+	if data == nil {
+		return "", fmt.Errorf("r1fs: data is required")
+	}
+	return c.addFileSource(ctx, readerSource(data), opts)
+}
+
+// AddFileFrom uploads the payload returned by open through /add_file, calling
+// open again to re-read the source whenever the request is retried.
+func (c *Client) AddFileFrom(ctx context.Context, open Opener, opts *DataOptions) (cid string, err error) {
+	if open == nil {
+		return "", fmt.Errorf("r1fs: opener is required")
+	}
+	return c.addFileSource(ctx, UploadSource{Open: open, Replayable: true}, opts)
+}
+
+func (c *Client) addFileSource(ctx context.Context, src UploadSource, opts *DataOptions) (string, error) {
 	if c == nil || c.backend == nil {
 		return "", fmt.Errorf("r1fs: client is nil")
 	}
-	payload, err := io.ReadAll(data)
+	if sb, ok := c.backend.(StreamingBackend); ok {
+		return sb.AddFileStream(ctx, src, opts)
+	}
+	payload, err := readSource(src)
 	if err != nil {
-		return "", fmt.Errorf("r1fs: read upload payload: %w", err)
+		return "", err
 	}
 	return c.backend.AddFile(ctx, payload, opts)
 }
 
 // GetFileBase64 retrieves and decodes data via /get_file_base64, returning the upstream filename.
+// With StrictNotFound set, missing CIDs are reported with an error wrapping
+// ErrNotFound.
 func (c *Client) GetFileBase64(ctx context.Context, cid string, secret string) (fileData []byte, fileName string, err error) {
 	if strings.TrimSpace(cid) == "" {
 		return nil, "", fmt.Errorf("r1fs: cid is required")
@@ -73,10 +127,22 @@ func (c *Client) GetFileBase64(ctx context.Context, cid string, secret string) (
 	if c == nil || c.backend == nil {
 		return nil, "", fmt.Errorf("r1fs: client is nil")
 	}
-	return c.backend.GetFileBase64(ctx, cid, secret)
+	fileData, fileName, err = c.backend.GetFileBase64(ctx, cid, secret)
+	if err != nil {
+		return nil, "", c.classifyError(cid, err)
+	}
+	if c.VerifyContent {
+		if err := verifyBytes(cid, secret, fileData); err != nil {
+			return nil, "", err
+		}
+	}
+	return fileData, fileName, nil
 }
 
-// GetFile resolves a CID to the on-disk path reported by /get_file.
+// GetFile resolves a CID to the on-disk path reported by /get_file. With
+// VerifyContent enabled the path must be readable from this host.
+// With StrictNotFound set, missing CIDs are reported with an error wrapping
+// ErrNotFound.
 func (c *Client) GetFile(ctx context.Context, cid string, secret string) (location *FileLocation, err error) {
 	if strings.TrimSpace(cid) == "" {
 		return nil, fmt.Errorf("r1fs: cid is required")
@@ -84,7 +150,16 @@ func (c *Client) GetFile(ctx context.Context, cid string, secret string) (locati
 	if c == nil || c.backend == nil {
 		return nil, fmt.Errorf("r1fs: client is nil")
 	}
-	return c.backend.GetFile(ctx, cid, secret)
+	location, err = c.backend.GetFile(ctx, cid, secret)
+	if err != nil {
+		return nil, c.classifyError(cid, err)
+	}
+	if c.VerifyContent {
+		if err := verifyFile(cid, secret, location.Path); err != nil {
+			return nil, err
+		}
+	}
+	return location, nil
 }
 
 // DeleteFile removes a single CID using the /delete_file endpoint.
@@ -151,6 +226,9 @@ func (c *Client) CalculateJSONCID(ctx context.Context, data any, nonce int, opts
 }
 
 // CalculatePickleCID deterministically calculates the CID for pickle data without storing it.
+// The HTTP backend asks the node via /calculate_pickle_cid. The nonce only
+// seeds the encryption of uploads with a secret, so without one the CID
+// depends on data alone and matches cid.SumPickle, which computes it offline.
 func (c *Client) CalculatePickleCID(ctx context.Context, data any, nonce int, opts *DataOptions) (cid string, err error) {
 	if data == nil {
 		return "", fmt.Errorf("r1fs: data is required")
@@ -182,6 +260,25 @@ func (c *Client) GetYAML(ctx context.Context, cid string, secret string, out any
 		return nil, err
 	}
 	doc, err = decodeYAMLDocument[json.RawMessage](cid, data)
+	if err != nil {
+		return nil, c.classifyError(cid, err)
+	}
+	if doc == nil || out == nil {
+		return doc, nil
+	}
+	if len(doc.Data) == 0 {
+		return doc, nil
+	}
+	if err := json.Unmarshal(doc.Data, out); err != nil {
+		return nil, fmt.Errorf("r1fs: decode YAML payload: %w", err)
+	}
+	return doc, nil
+}
+
+// GetJSON retrieves a JSON document stored with AddJSON as raw JSON. Provide
+// out to decode into a struct.
+func (c *Client) GetJSON(ctx context.Context, cid string, secret string, out any) (doc *JSONDocument[json.RawMessage], err error) {
+	doc, err = GetJSONAs[json.RawMessage](ctx, c, cid, secret)
 	if err != nil || doc == nil || out == nil {
 		return doc, err
 	}
@@ -189,11 +286,108 @@ func (c *Client) GetYAML(ctx context.Context, cid string, secret string, out any
 		return doc, nil
 	}
 	if err := json.Unmarshal(doc.Data, out); err != nil {
-		return nil, fmt.Errorf("r1fs: decode YAML payload: %w", err)
+		return nil, fmt.Errorf("r1fs: decode JSON payload: %w", err)
+	}
+	return doc, nil
+}
+
+// GetJSONAs retrieves a JSON document stored with AddJSON and decodes it into
+// T. Empty documents yield a nil result.
+func GetJSONAs[T any](ctx context.Context, c *Client, cid string, secret string) (*JSONDocument[T], error) {
+	data, err := c.getDocumentRaw(ctx, "get_json", cid, secret)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("r1fs: decode JSON payload: %w", err)
+	}
+	return &JSONDocument[T]{CID: cid, Data: value}, nil
+}
+
+// GetPickle retrieves an object stored with AddPickle and decodes the pickle
+// (protocols 2 to 5) into plain Go values with pickle.Unmarshal. Provide out to
+// convert the decoded value into a struct through its JSON representation.
+func (c *Client) GetPickle(ctx context.Context, cid string, secret string, out any) (doc *PickleDocument, err error) {
+	data, err := c.getDocumentRaw(ctx, "get_pickle", cid, secret)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	value, err := pickle.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("r1fs: %w", err)
+	}
+	doc = &PickleDocument{CID: cid, Data: value}
+	if out == nil {
+		return doc, nil
+	}
+	// Shared references are expanded by the JSON round trip; refuse values
+	// that would blow up.
+	budget := maxPickleConvertElements
+	if !withinElements(value, &budget) {
+		return nil, fmt.Errorf("r1fs: convert pickle payload: more than %d elements once shared references are expanded", maxPickleConvertElements)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("r1fs: convert pickle payload: %w", err)
+	}
+	if err := json.Unmarshal(encoded, out); err != nil {
+		return nil, fmt.Errorf("r1fs: decode pickle payload: %w", err)
 	}
 	return doc, nil
 }
 
+// maxPickleConvertElements bounds the expanded size of a pickle converted
+// into out by GetPickle.
+const maxPickleConvertElements = 1 << 24
+
+// withinElements reports whether v, with shared values counted every time
+// they appear, holds at most *budget list and map elements.
+func withinElements(v any, budget *int) bool {
+	switch t := v.(type) {
+	case []any:
+		if *budget -= len(t); *budget < 0 {
+			return false
+		}
+		for _, item := range t {
+			if !withinElements(item, budget) {
+				return false
+			}
+		}
+	case map[string]any:
+		if *budget -= len(t); *budget < 0 {
+			return false
+		}
+		for _, item := range t {
+			if !withinElements(item, budget) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// getDocumentRaw downloads a stored document. Like decodeYAMLDocument it
+// treats empty or null content as absent and reports the upstream "error"
+// string as a failure, classified by classifyError.
+func (c *Client) getDocumentRaw(ctx context.Context, op string, cid string, secret string) ([]byte, error) {
+	if c == nil {
+		return nil, fmt.Errorf("r1fs: client is nil")
+	}
+	data, _, err := c.GetFileBase64(ctx, cid, secret)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, nil
+	}
+	if isErrorResult(trimmed) {
+		return nil, c.classifyError(cid, fmt.Errorf("r1fs: %s reported error for cid %s: %w", op, cid, errErrorResult))
+	}
+	return data, nil
+}
+
 func (c *Client) getYAMLRaw(ctx context.Context, cid string, secret string) ([]byte, error) {
 	if strings.TrimSpace(cid) == "" {
 		return nil, fmt.Errorf("r1fs: cid is required")
@@ -201,7 +395,11 @@ func (c *Client) getYAMLRaw(ctx context.Context, cid string, secret string) ([]b
 	if c == nil || c.backend == nil {
 		return nil, fmt.Errorf("r1fs: client is nil")
 	}
-	return c.backend.GetYAML(ctx, cid, secret)
+	data, err := c.backend.GetYAML(ctx, cid, secret)
+	if err != nil {
+		return nil, c.classifyError(cid, err)
+	}
+	return data, nil
 }
 
 func cloneMeta(src map[string]any) map[string]any {
@@ -225,17 +423,42 @@ func encodeJSON(payload any) ([]byte, error) {
 	return bytes.TrimRight(buf.Bytes(), "\n"), nil
 }
 
+// errErrorResult marks the bare "error" string the R1FS manager returns when
+// a CID cannot be resolved.
+var errErrorResult = errors.New("upstream error result")
+
+// classifyError maps upstream 404 responses and "error" results onto
+// ErrNotFound when StrictNotFound is set, so callers can rely on errors.Is
+// regardless of the body the node returned.
+func (c *Client) classifyError(cid string, err error) error {
+	if err == nil || !c.StrictNotFound || errors.Is(err, ErrNotFound) {
+		return err
+	}
+	var httpErr *httpx.HTTPError
+	if errors.Is(err, errErrorResult) || (errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound) {
+		return fmt.Errorf("r1fs: cid %s: %w: %w", cid, ErrNotFound, err)
+	}
+	return err
+}
+
+// isErrorResult reports whether payload is the bare "error" string the R1FS
+// manager returns when a CID cannot be resolved.
+func isErrorResult(payload []byte) bool {
+	var str string
+	if err := json.Unmarshal(bytes.TrimSpace(payload), &str); err != nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(str), "error")
+}
+
 func decodeYAMLDocument[T any](cid string, data []byte) (*YAMLDocument[T], error) {
 	trimmed := bytes.TrimSpace(data)
 	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
 		return nil, nil
 	}
 
-	var str string
-	if err := json.Unmarshal(trimmed, &str); err == nil {
-		if strings.EqualFold(str, "error") {
-			return nil, fmt.Errorf("r1fs: get_yaml reported error for cid %s", cid)
-		}
+	if isErrorResult(trimmed) {
+		return nil, fmt.Errorf("r1fs: get_yaml reported error for cid %s: %w", cid, errErrorResult)
 	}
 
 	var payload struct {
@@ -322,61 +545,7 @@ func (b *httpBackend) AddFileBase64(ctx context.Context, data []byte, opts *Data
 }
 
 func (b *httpBackend) AddFile(ctx context.Context, data []byte, opts *DataOptions) (cid string, err error) {
-	if b == nil || b.client == nil {
-		return "", fmt.Errorf("r1fs: http backend not configured")
-	}
-	filename := resolveUploadName(opts)
-	if strings.TrimSpace(filename) == "" {
-		return "", fmt.Errorf("r1fs: filename or filepath is required")
-	}
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	filePart, err := writer.CreateFormFile("file", filename)
-	if err != nil {
-		return "", fmt.Errorf("r1fs: create multipart part: %w", err)
-	}
-	if _, err := filePart.Write(data); err != nil {
-		return "", fmt.Errorf("r1fs: write multipart payload: %w", err)
-	}
-	meta := applyBodyJSON(opts)
-	metaBytes, err := json.Marshal(meta)
-	if err != nil {
-		return "", fmt.Errorf("r1fs: encode multipart metadata: %w", err)
-	}
-	if err := writer.WriteField("body_json", string(metaBytes)); err != nil {
-		return "", fmt.Errorf("r1fs: write multipart metadata: %w", err)
-	}
-	if err := writer.Close(); err != nil {
-		return "", fmt.Errorf("r1fs: finalize multipart body: %w", err)
-	}
-	payload := body.Bytes()
-	req := &httpx.Request{
-		Method: http.MethodPost,
-		Path:   "add_file",
-		Header: http.Header{"Content-Type": []string{writer.FormDataContentType()}},
-		Body:   bytes.NewReader(payload),
-		GetBody: func() (io.ReadCloser, error) {
-			return io.NopCloser(bytes.NewReader(payload)), nil
-		},
-	}
-	resp, err := b.client.Do(ctx, req)
-	if err != nil {
-		return "", err
-	}
-	payloadBytes, err := httpx.ReadAllAndClose(resp.Body)
-	if err != nil {
-		return "", err
-	}
-	var response struct {
-		CID string `json:"cid"`
-	}
-	if err := ratio1api.DecodeResult(payloadBytes, &response); err != nil {
-		return "", fmt.Errorf("r1fs: decode add_file response: %w", err)
-	}
-	if strings.TrimSpace(response.CID) == "" {
-		return "", fmt.Errorf("r1fs: missing cid in add_file response")
-	}
-	return response.CID, nil
+	return b.AddFileStream(ctx, bytesSource(data), opts)
 }
 
 func (b *httpBackend) AddJSON(ctx context.Context, data any, opts *DataOptions) (cid string, err error) {
@@ -387,7 +556,7 @@ func (b *httpBackend) AddJSON(ctx context.Context, data any, opts *DataOptions)
 		"data": data,
 	}
 	applyDataOptions(payload, opts)
-	return b.postCIDRequest(ctx, "add_json", payload, "add_json")
+	return b.postCIDRequest(ctx, "add_json", payload, httpx.NonIdempotent)
 }
 
 func (b *httpBackend) AddPickle(ctx context.Context, data any, opts *DataOptions) (cid string, err error) {
@@ -398,7 +567,7 @@ func (b *httpBackend) AddPickle(ctx context.Context, data any, opts *DataOptions
 		"data": data,
 	}
 	applyDataOptions(payload, opts)
-	return b.postCIDRequest(ctx, "add_pickle", payload, "add_pickle")
+	return b.postCIDRequest(ctx, "add_pickle", payload, httpx.NonIdempotent)
 }
 
 func (b *httpBackend) CalculateJSONCID(ctx context.Context, data any, nonce int, opts *DataOptions) (cid string, err error) {
@@ -410,7 +579,7 @@ func (b *httpBackend) CalculateJSONCID(ctx context.Context, data any, nonce int,
 		"nonce": nonce,
 	}
 	applyDataOptions(payload, opts)
-	return b.postCIDRequest(ctx, "calculate_json_cid", payload, "calculate_json_cid")
+	return b.postCIDRequest(ctx, "calculate_json_cid", payload, httpx.Idempotent)
 }
 
 func (b *httpBackend) CalculatePickleCID(ctx context.Context, data any, nonce int, opts *DataOptions) (cid string, err error) {
@@ -422,7 +591,7 @@ func (b *httpBackend) CalculatePickleCID(ctx context.Context, data any, nonce in
 		"nonce": nonce,
 	}
 	applyDataOptions(payload, opts)
-	return b.postCIDRequest(ctx, "calculate_pickle_cid", payload, "calculate_pickle_cid")
+	return b.postCIDRequest(ctx, "calculate_pickle_cid", payload, httpx.Idempotent)
 }
 
 func (b *httpBackend) GetFileBase64(ctx context.Context, cid string, secret string) (fileData []byte, fileName string, err error) {
@@ -440,10 +609,11 @@ func (b *httpBackend) GetFileBase64(ctx context.Context, cid string, secret stri
 		return nil, "", err
 	}
 	req := &httpx.Request{
-		Method: http.MethodPost,
-		Path:   "get_file_base64",
-		Header: http.Header{"Content-Type": []string{"application/json"}},
-		Body:   bytes.NewReader(jsonBody),
+		Method:      http.MethodPost,
+		Path:        "get_file_base64",
+		Idempotency: httpx.Idempotent,
+		Header:      http.Header{"Content-Type": []string{"application/json"}},
+		Body:        bytes.NewReader(jsonBody),
 		GetBody: func() (io.ReadCloser, error) {
 			return io.NopCloser(bytes.NewReader(jsonBody)), nil
 		},
@@ -460,8 +630,8 @@ func (b *httpBackend) GetFileBase64(ctx context.Context, cid string, secret stri
 		FileBase64 string `json:"file_base64_str"`
 		Filename   string `json:"filename"`
 	}
-	if err := ratio1api.DecodeResult(payloadBytes, &result); err != nil {
-		return nil, "", fmt.Errorf("r1fs: decode get_file_base64 response: %w", err)
+	if err := decodeLookupResult(payloadBytes, "get_file_base64", cid, &result); err != nil {
+		return nil, "", err
 	}
 	data, err := base64.StdEncoding.DecodeString(result.FileBase64)
 	if err != nil {
@@ -494,8 +664,8 @@ func (b *httpBackend) GetFile(ctx context.Context, cid string, secret string) (l
 		FilePath string         `json:"file_path"`
 		Meta     map[string]any `json:"meta"`
 	}
-	if err := ratio1api.DecodeResult(payloadBytes, &payload); err != nil {
-		return nil, fmt.Errorf("r1fs: decode get_file response: %w", err)
+	if err := decodeLookupResult(payloadBytes, "get_file", cid, &payload); err != nil {
+		return nil, err
 	}
 	loc := &FileLocation{
 		Path: payload.FilePath,
@@ -529,10 +699,11 @@ func (b *httpBackend) DeleteFile(ctx context.Context, cid string, opts *DeleteOp
 		return nil, err
 	}
 	req := &httpx.Request{
-		Method: http.MethodPost,
-		Path:   "delete_file",
-		Header: http.Header{"Content-Type": []string{"application/json"}},
-		Body:   bytes.NewReader(jsonBody),
+		Method:      http.MethodPost,
+		Path:        "delete_file",
+		Idempotency: httpx.Idempotent,
+		Header:      http.Header{"Content-Type": []string{"application/json"}},
+		Body:        bytes.NewReader(jsonBody),
 		GetBody: func() (io.ReadCloser, error) {
 			return io.NopCloser(bytes.NewReader(jsonBody)), nil
 		},
@@ -568,10 +739,11 @@ func (b *httpBackend) DeleteFiles(ctx context.Context, cids []string, opts *Dele
 		return nil, err
 	}
 	req := &httpx.Request{
-		Method: http.MethodPost,
-		Path:   "delete_files",
-		Header: http.Header{"Content-Type": []string{"application/json"}},
-		Body:   bytes.NewReader(jsonBody),
+		Method:      http.MethodPost,
+		Path:        "delete_files",
+		Idempotency: httpx.Idempotent,
+		Header:      http.Header{"Content-Type": []string{"application/json"}},
+		Body:        bytes.NewReader(jsonBody),
 		GetBody: func() (io.ReadCloser, error) {
 			return io.NopCloser(bytes.NewReader(jsonBody)), nil
 		},
@@ -662,16 +834,36 @@ func (b *httpBackend) GetYAML(ctx context.Context, cid string, secret string) (p
 	return data, nil
 }
 
-func (b *httpBackend) postCIDRequest(ctx context.Context, path string, payload map[string]any, op string) (string, error) {
+// decodeLookupResult decodes a CID lookup response into out, reporting the
+// upstream "error" string as an error wrapping errErrorResult.
+func decodeLookupResult(body []byte, op, cid string, out any) error {
+	payload, err := ratio1api.ExtractResult(body)
+	if err != nil {
+		return fmt.Errorf("r1fs: decode %s response: %w", op, err)
+	}
+	if isErrorResult(payload) {
+		return fmt.Errorf("r1fs: %s reported error for cid %s: %w", op, cid, errErrorResult)
+	}
+	if len(payload) == 0 {
+		payload = []byte("null")
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return fmt.Errorf("r1fs: decode %s response: %w", op, err)
+	}
+	return nil
+}
+
+func (b *httpBackend) postCIDRequest(ctx context.Context, path string, payload map[string]any, idempotency httpx.Idempotency) (string, error) {
 	jsonBody, err := encodeJSON(payload)
 	if err != nil {
 		return "", err
 	}
 	req := &httpx.Request{
-		Method: http.MethodPost,
-		Path:   path,
-		Header: http.Header{"Content-Type": []string{"application/json"}},
-		Body:   bytes.NewReader(jsonBody),
+		Method:      http.MethodPost,
+		Path:        path,
+		Idempotency: idempotency,
+		Header:      http.Header{"Content-Type": []string{"application/json"}},
+		Body:        bytes.NewReader(jsonBody),
 		GetBody: func() (io.ReadCloser, error) {
 			return io.NopCloser(bytes.NewReader(jsonBody)), nil
 		},
@@ -688,10 +880,10 @@ func (b *httpBackend) postCIDRequest(ctx context.Context, path string, payload m
 		CID string `json:"cid"`
 	}
 	if err := ratio1api.DecodeResult(payloadBytes, &response); err != nil {
-		return "", fmt.Errorf("r1fs: decode %s response: %w", op, err)
+		return "", fmt.Errorf("r1fs: decode %s response: %w", path, err)
 	}
 	if strings.TrimSpace(response.CID) == "" {
-		return "", fmt.Errorf("r1fs: missing cid in %s response", op)
+		return "", fmt.Errorf("r1fs: missing cid in %s response", path)
 	}
 	return response.CID, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -13,6 +14,7 @@ import (
 	"testing"
 
 	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1transport"
 )
 
 func TestAddFileBase64AndGetFileBase64(t *testing.T) {
@@ -460,3 +462,46 @@ func newLocalHTTPServer(t *testing.T, handler http.Handler) *testServer {
 	}()
 	return ts
 }
+
+func TestLookupErrorsMapToNotFound(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/get_file_base64":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"detail":"not found"}`))
+		case "/get_file":
+			_, _ = w.Write([]byte(`{"result":"error"}`))
+		case "/get_yaml":
+			_, _ = w.Write([]byte(`"error"`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv := newLocalHTTPServer(t, handler)
+	defer srv.Close()
+
+	client, err := r1fs.New(srv.URL, r1transport.WithRetryPolicy(r1transport.RetryPolicy{MaxRetries: 0}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, _, err := client.GetFileBase64(ctx, "QmMissing", ""); err == nil || errors.Is(err, r1fs.ErrNotFound) {
+		t.Fatalf("lenient GetFileBase64: expected the HTTP error, got %v", err)
+	}
+	if _, err := client.GetFile(ctx, "QmMissing", ""); err == nil || errors.Is(err, r1fs.ErrNotFound) {
+		t.Fatalf("lenient GetFile: expected the upstream error, got %v", err)
+	}
+
+	client.StrictNotFound = true
+	if _, _, err := client.GetFileBase64(ctx, "QmMissing", ""); !errors.Is(err, r1fs.ErrNotFound) {
+		t.Fatalf("GetFileBase64: expected ErrNotFound, got %v", err)
+	}
+	if _, err := client.GetFile(ctx, "QmMissing", ""); !errors.Is(err, r1fs.ErrNotFound) {
+		t.Fatalf("GetFile: expected ErrNotFound, got %v", err)
+	}
+	if _, err := client.GetYAML(ctx, "QmMissing", "", nil); !errors.Is(err, r1fs.ErrNotFound) {
+		t.Fatalf("GetYAML: expected ErrNotFound, got %v", err)
+	}
+}
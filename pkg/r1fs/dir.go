@@ -0,0 +1,312 @@
+package r1fs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ManifestVersion is the DirManifest layout written by AddDir.
+const ManifestVersion = 1
+
+const (
+	defaultDirConcurrency = 4
+	manifestFilename      = "manifest.json"
+)
+
+// AddDir uploads every regular file in fsys through AddFileFrom, stores a
+// DirManifest describing them with AddJSON and returns the manifest CID.
+// Symlinks and other irregular files are skipped and empty directories are not
+// recorded. Files are uploaded in parallel; the first failure cancels the rest.
+func (c *Client) AddDir(ctx context.Context, fsys fs.FS, opts *DirOptions) (cid string, err error) {
+	if fsys == nil {
+		return "", fmt.Errorf("r1fs: file system is required")
+	}
+	if c == nil || c.backend == nil {
+		return "", fmt.Errorf("r1fs: client is nil")
+	}
+	var names []string
+	err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("r1fs: walk directory: %w", err)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("r1fs: directory contains no files")
+	}
+
+	secret := dirSecret(opts)
+	files := make([]DirEntry, len(names))
+	err = forEach(ctx, len(names), dirConcurrency(opts), func(ctx context.Context, i int) error {
+		entry, err := c.addDirEntry(ctx, fsys, names[i], secret)
+		if err != nil {
+			return err
+		}
+		files[i] = entry
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	manifest := &DirManifest{Version: ManifestVersion, Files: files}
+	dataOpts := &DataOptions{Filename: manifestFilename, Secret: secret}
+	if opts != nil {
+		dataOpts.Nonce = opts.Nonce
+	}
+	return c.AddJSON(ctx, manifest, dataOpts)
+}
+
+// AddDirPath uploads the tree rooted at root on the local filesystem. See
+// AddDir.
+func (c *Client) AddDirPath(ctx context.Context, root string, opts *DirOptions) (cid string, err error) {
+	if strings.TrimSpace(root) == "" {
+		return "", fmt.Errorf("r1fs: directory path is required")
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return "", fmt.Errorf("r1fs: stat directory: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("r1fs: %s is not a directory", root)
+	}
+	return c.AddDir(ctx, os.DirFS(root), opts)
+}
+
+func (c *Client) addDirEntry(ctx context.Context, fsys fs.FS, name string, secret string) (DirEntry, error) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return DirEntry{}, fmt.Errorf("r1fs: stat %s: %w", name, err)
+	}
+	// Every attempt gets a fresh digest; the last one belongs to the attempt
+	// that succeeded.
+	var last *digestReader
+	open := func() (io.ReadCloser, error) {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		last = &digestReader{rc: f, h: sha256.New()}
+		return last, nil
+	}
+	cid, err := c.AddFileFrom(ctx, open, &DataOptions{Filename: path.Base(name), Secret: secret})
+	if err != nil {
+		return DirEntry{}, fmt.Errorf("r1fs: upload %s: %w", name, err)
+	}
+	return DirEntry{
+		Path:   name,
+		CID:    cid,
+		Size:   last.n,
+		Mode:   info.Mode().Perm(),
+		SHA256: hex.EncodeToString(last.h.Sum(nil)),
+	}, nil
+}
+
+// GetDir downloads the manifest stored by AddDir and restores its files under
+// dest, creating directories as needed. Each file is checked against the size
+// and SHA-256 recorded in the manifest; mismatches return an error wrapping
+// ErrIntegrity. Manifest paths that would escape dest, duplicate another
+// entry or conflict with another entry's directory are rejected before
+// anything is written.
+func (c *Client) GetDir(ctx context.Context, manifestCID string, dest string, opts *DirOptions) (manifest *DirManifest, err error) {
+	if strings.TrimSpace(dest) == "" {
+		return nil, fmt.Errorf("r1fs: destination directory is required")
+	}
+	secret := dirSecret(opts)
+	manifest, err = c.loadDirManifest(ctx, manifestCID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return nil, fmt.Errorf("r1fs: create destination: %w", err)
+	}
+	err = forEach(ctx, len(manifest.Files), dirConcurrency(opts), func(ctx context.Context, i int) error {
+		return c.restoreDirEntry(ctx, dest, manifest.Files[i], secret)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (c *Client) restoreDirEntry(ctx context.Context, dest string, entry DirEntry, secret string) (err error) {
+	target := filepath.Join(dest, filepath.FromSlash(entry.Path))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("r1fs: create directory for %s: %w", entry.Path, err)
+	}
+	perm := entry.Mode.Perm()
+	if perm == 0 {
+		perm = 0o644
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("r1fs: create %s: %w", entry.Path, err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("r1fs: write %s: %w", entry.Path, cerr)
+		}
+		if err != nil {
+			_ = os.Remove(target)
+		}
+	}()
+
+	h := sha256.New()
+	info, err := c.GetFileTo(ctx, entry.CID, secret, io.MultiWriter(f, h))
+	if err != nil {
+		return fmt.Errorf("r1fs: download %s: %w", entry.Path, err)
+	}
+	if err := checkDirEntry(entry, info.Size, h.Sum(nil)); err != nil {
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		return fmt.Errorf("r1fs: set mode of %s: %w", entry.Path, err)
+	}
+	return nil
+}
+
+// loadDirManifest downloads a DirManifest and rejects entries whose paths
+// are not valid slash-separated relative paths, appear more than once or
+// name a file that another entry uses as a directory.
+func (c *Client) loadDirManifest(ctx context.Context, manifestCID string, secret string) (*DirManifest, error) {
+	doc, err := GetJSONAs[DirManifest](ctx, c, manifestCID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("r1fs: manifest %s is empty: %w", manifestCID, ErrNotFound)
+	}
+	manifest := &doc.Data
+	if manifest.Version != ManifestVersion {
+		return nil, fmt.Errorf("r1fs: manifest %s has unsupported version %d", manifestCID, manifest.Version)
+	}
+	paths := make(map[string]bool, len(manifest.Files))
+	for _, entry := range manifest.Files {
+		if !fs.ValidPath(entry.Path) || entry.Path == "." || strings.Contains(entry.Path, `\`) {
+			return nil, fmt.Errorf("r1fs: manifest %s contains invalid path %q", manifestCID, entry.Path)
+		}
+		if strings.TrimSpace(entry.CID) == "" {
+			return nil, fmt.Errorf("r1fs: manifest %s has no cid for %s", manifestCID, entry.Path)
+		}
+		if entry.Size < 0 {
+			return nil, fmt.Errorf("r1fs: manifest %s lists negative size %d for %s", manifestCID, entry.Size, entry.Path)
+		}
+		if paths[entry.Path] {
+			return nil, fmt.Errorf("r1fs: manifest %s lists %s more than once", manifestCID, entry.Path)
+		}
+		paths[entry.Path] = true
+	}
+	// A path cannot be both a file and the parent of another entry.
+	for _, entry := range manifest.Files {
+		for dir := path.Dir(entry.Path); dir != "."; dir = path.Dir(dir) {
+			if paths[dir] {
+				return nil, fmt.Errorf("r1fs: manifest %s lists %s as a file and as the parent of %s", manifestCID, dir, entry.Path)
+			}
+		}
+	}
+	return manifest, nil
+}
+
+// checkDirEntry compares downloaded content with its manifest entry.
+func checkDirEntry(entry DirEntry, size int64, sum []byte) error {
+	if size != entry.Size {
+		return fmt.Errorf("r1fs: %s has %d bytes, manifest lists %d: %w", entry.Path, size, entry.Size, ErrIntegrity)
+	}
+	if got := hex.EncodeToString(sum); entry.SHA256 != "" && !strings.EqualFold(got, entry.SHA256) {
+		return fmt.Errorf("r1fs: %s hashes to sha256 %s, manifest lists %s: %w", entry.Path, got, entry.SHA256, ErrIntegrity)
+	}
+	return nil
+}
+
+func dirSecret(opts *DirOptions) string {
+	if opts == nil {
+		return ""
+	}
+	return opts.Secret
+}
+
+func dirConcurrency(opts *DirOptions) int {
+	if opts == nil || opts.Concurrency <= 0 {
+		return defaultDirConcurrency
+	}
+	return opts.Concurrency
+}
+
+// forEach calls fn for every index below n on at most limit goroutines. The
+// first error cancels the context passed to the remaining calls and is
+// returned.
+func forEach(ctx context.Context, n, limit int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if limit > n {
+		limit = n
+	}
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	next := make(chan int)
+	for w := 0; w < limit; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() {
+						first = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+	if first != nil {
+		return first
+	}
+	return ctx.Err()
+}
+
+// digestReader hashes and counts the bytes read from rc.
+type digestReader struct {
+	rc io.ReadCloser
+	h  hash.Hash
+	n  int64
+}
+
+func (r *digestReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	r.h.Write(p[:n])
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *digestReader) Close() error {
+	return r.rc.Close()
+}
@@ -0,0 +1,151 @@
+package r1fs_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/memfs"
+)
+
+func TestAddDirGetDirRoundTrip(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+	ctx := context.Background()
+
+	bundle := fstest.MapFS{
+		"config.json":            {Data: []byte(`{"layers": 2}`), Mode: 0o644},
+		"weights/model.bin":      {Data: []byte(strings.Repeat("\x00\x01", 4096)), Mode: 0o600},
+		"tokenizer/vocab.txt":    {Data: []byte("a\nb\nc\n"), Mode: 0o644},
+		"tokenizer/merges/empty": {Data: nil, Mode: 0o644},
+		"tokenizer/run.sh":       {Data: []byte("#!/bin/sh\n"), Mode: 0o755},
+		"assets":                 {Mode: os.ModeDir | 0o755},
+	}
+	manifestCID, err := client.AddDir(ctx, bundle, &r1fs.DirOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("AddDir: %v", err)
+	}
+
+	dest := t.TempDir()
+	manifest, err := client.GetDir(ctx, manifestCID, dest, nil)
+	if err != nil {
+		t.Fatalf("GetDir: %v", err)
+	}
+	if manifest.Version != r1fs.ManifestVersion || len(manifest.Files) != 5 {
+		t.Fatalf("unexpected manifest: %#v", manifest)
+	}
+	if manifest.Files[0].Path != "config.json" || manifest.Files[0].Size != 13 {
+		t.Fatalf("expected sorted entries with sizes, got %#v", manifest.Files[0])
+	}
+	for name, file := range bundle {
+		if file.Mode.IsDir() {
+			continue
+		}
+		target := filepath.Join(dest, filepath.FromSlash(name))
+		data, err := os.ReadFile(target)
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if string(data) != string(file.Data) {
+			t.Fatalf("%s restored with different content", name)
+		}
+		info, err := os.Stat(target)
+		if err != nil {
+			t.Fatalf("stat %s: %v", name, err)
+		}
+		if info.Mode().Perm() != file.Mode.Perm() {
+			t.Fatalf("%s restored with mode %v, want %v", name, info.Mode().Perm(), file.Mode.Perm())
+		}
+	}
+}
+
+func TestGetDirRejectsUnsafeAndTamperedEntries(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+	ctx := context.Background()
+
+	cid, err := client.AddFile(ctx, strings.NewReader("payload"), &r1fs.DataOptions{Filename: "payload.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+
+	escaping, err := client.AddJSON(ctx, r1fs.DirManifest{
+		Version: r1fs.ManifestVersion,
+		Files:   []r1fs.DirEntry{{Path: "../escape.txt", CID: cid, Size: 7}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("AddJSON: %v", err)
+	}
+	parent := t.TempDir()
+	dest := filepath.Join(parent, "out")
+	if _, err := client.GetDir(ctx, escaping, dest, nil); err == nil || !strings.Contains(err.Error(), "invalid path") {
+		t.Fatalf("expected invalid path error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(parent, "escape.txt")); !os.IsNotExist(err) {
+		t.Fatalf("escaping entry was written: %v", err)
+	}
+
+	tampered, err := client.AddJSON(ctx, r1fs.DirManifest{
+		Version: r1fs.ManifestVersion,
+		Files: []r1fs.DirEntry{{
+			Path:   "payload.txt",
+			CID:    cid,
+			Size:   7,
+			SHA256: strings.Repeat("0", 64),
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("AddJSON: %v", err)
+	}
+	if _, err := client.GetDir(ctx, tampered, dest, nil); !errors.Is(err, r1fs.ErrIntegrity) {
+		t.Fatalf("expected ErrIntegrity, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "payload.txt")); !os.IsNotExist(err) {
+		t.Fatalf("mismatching file was kept: %v", err)
+	}
+}
+
+func TestDirManifestRejectsConflictingPaths(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+	ctx := context.Background()
+
+	cid, err := client.AddFile(ctx, strings.NewReader("payload"), &r1fs.DataOptions{Filename: "payload.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	cases := map[string][]string{
+		"duplicate":       {"a.txt", "a.txt"},
+		"file then dir":   {"a", "a/b"},
+		"dir then file":   {"a/b/c", "a"},
+		"nested conflict": {"a/b", "a/b/c/d"},
+	}
+	for name, paths := range cases {
+		entries := make([]r1fs.DirEntry, len(paths))
+		for i, p := range paths {
+			entries[i] = r1fs.DirEntry{Path: p, CID: cid, Size: 7}
+		}
+		manifestCID, err := client.AddJSON(ctx, r1fs.DirManifest{Version: r1fs.ManifestVersion, Files: entries}, nil)
+		if err != nil {
+			t.Fatalf("%s: AddJSON: %v", name, err)
+		}
+		dest := filepath.Join(t.TempDir(), "out")
+		if _, err := client.GetDir(ctx, manifestCID, dest, nil); err == nil {
+			t.Fatalf("%s: GetDir accepted %v", name, paths)
+		}
+		if _, err := os.Stat(dest); !os.IsNotExist(err) {
+			t.Fatalf("%s: GetDir wrote %s: %v", name, dest, err)
+		}
+		if _, err := r1fs.NewFS(client, manifestCID, "").Stat(paths[0]); err == nil {
+			t.Fatalf("%s: FS accepted %v", name, paths)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package r1fs_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/memfs"
+)
+
+func TestGetJSONRoundTrip(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+	ctx := context.Background()
+
+	type config struct {
+		Service string `json:"service"`
+		Workers int    `json:"workers"`
+	}
+	cid, err := client.AddJSON(ctx, config{Service: "r1fs", Workers: 4}, nil)
+	if err != nil {
+		t.Fatalf("AddJSON: %v", err)
+	}
+
+	var out config
+	doc, err := client.GetJSON(ctx, cid, "", &out)
+	if err != nil {
+		t.Fatalf("GetJSON: %v", err)
+	}
+	if doc == nil || doc.CID != cid || out.Workers != 4 {
+		t.Fatalf("unexpected document %+v out=%+v", doc, out)
+	}
+
+	typed, err := r1fs.GetJSONAs[config](ctx, client, cid, "")
+	if err != nil {
+		t.Fatalf("GetJSONAs: %v", err)
+	}
+	if typed.Data.Service != "r1fs" {
+		t.Fatalf("unexpected typed document %+v", typed)
+	}
+
+	errCID, err := client.AddFile(ctx, strings.NewReader(`"error"`), &r1fs.DataOptions{Filename: "e.json"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	if _, err := client.GetJSON(ctx, errCID, "", nil); err == nil || errors.Is(err, r1fs.ErrNotFound) {
+		t.Fatalf("expected a plain error for the error string, got %v", err)
+	}
+	client.StrictNotFound = true
+	if _, err := client.GetJSON(ctx, errCID, "", nil); !errors.Is(err, r1fs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for the error string, got %v", err)
+	}
+}
+
+// Fixtures are pickle.dumps(obj, protocol=N) of
+// {'name': 'r1fs', 'count': 3, 'ratio': 0.5, 'ok': True, 'none': None,
+// 'tags': ['a', 'b'], 'pair': (1, 2), 'blob': b'\x00\x01', 'big': 2**70,
+// 'neg': -300, 1: 'one', 'set': {7}, 'nested': {'k': [1.5, 'é']}}.
+var pickleFixtures = map[int]string{
+	2: "80027d71002858040000006e616d65710158040000007231667371025805000000636f756e7471034b035805000000726174696f7104473fe000000000000058020000006f6b71058858040000006e6f6e6571064e58040000007461677371075d7108285801000000617109580100000062710a65580400000070616972710b4b014b0286710c5804000000626c6f62710d635f636f646563730a656e636f64650a710e58020000000001710f58060000006c6174696e317110867111527112580300000062696771138a0900000000000000004058030000006e656771144ad4feffff4b0158030000006f6e65711558030000007365747116635f5f6275696c74696e5f5f0a7365740a71175d71184b076185711952711a58060000006e6573746564711b7d711c58010000006b711d5d711e28473ff80000000000005802000000c3a9711f6573752e",
+	3: "80037d71002858040000006e616d65710158040000007231667371025805000000636f756e7471034b035805000000726174696f7104473fe000000000000058020000006f6b71058858040000006e6f6e6571064e58040000007461677371075d7108285801000000617109580100000062710a65580400000070616972710b4b014b0286710c5804000000626c6f62710d43020001710e5803000000626967710f8a0900000000000000004058030000006e656771104ad4feffff4b0158030000006f6e65711158030000007365747112636275696c74696e730a7365740a71135d71144b076185711552711658060000006e657374656471177d711858010000006b71195d711a28473ff80000000000005802000000c3a9711b6573752e",
+	4: "800495ba000000000000007d94288c046e616d65948c0472316673948c05636f756e74944b038c05726174696f94473fe00000000000008c026f6b94888c046e6f6e65944e8c0474616773945d94288c0161948c016294658c0470616972944b014b0286948c04626c6f629443020001948c03626967948a090000000000000000408c036e6567944ad4feffff4b018c036f6e65948c03736574948f94284b07908c066e6573746564947d948c016b945d9428473ff80000000000008c02c3a9946573752e",
+	5: "800595ba000000000000007d94288c046e616d65948c0472316673948c05636f756e74944b038c05726174696f94473fe00000000000008c026f6b94888c046e6f6e65944e8c0474616773945d94288c0161948c016294658c0470616972944b014b0286948c04626c6f629443020001948c03626967948a090000000000000000408c036e6567944ad4feffff4b018c036f6e65948c03736574948f94284b07908c066e6573746564947d948c016b945d9428473ff80000000000008c02c3a9946573752e",
+}
+
+func TestGetPickleDecodesProtocols(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+	ctx := context.Background()
+
+	big70 := new(big.Int).Lsh(big.NewInt(1), 70)
+	want := map[string]any{
+		"name": "r1fs", "count": int64(3), "ratio": 0.5, "ok": true, "none": nil,
+		"tags": []any{"a", "b"}, "pair": []any{int64(1), int64(2)}, "blob": []byte{0, 1},
+		"big": big70, "neg": int64(-300), "1": "one", "set": []any{int64(7)},
+		"nested": map[string]any{"k": []any{1.5, "é"}},
+	}
+	for proto, fixture := range pickleFixtures {
+		raw, err := hex.DecodeString(fixture)
+		if err != nil {
+			t.Fatalf("fixture %d: %v", proto, err)
+		}
+		cid, err := client.AddFile(ctx, bytes.NewReader(raw), &r1fs.DataOptions{Filename: "obj.pkl"})
+		if err != nil {
+			t.Fatalf("AddFile: %v", err)
+		}
+		var out struct {
+			Name   string `json:"name"`
+			Count  int    `json:"count"`
+			Nested struct {
+				K []any `json:"k"`
+			} `json:"nested"`
+		}
+		doc, err := client.GetPickle(ctx, cid, "", &out)
+		if err != nil {
+			t.Fatalf("protocol %d: GetPickle: %v", proto, err)
+		}
+		if !reflect.DeepEqual(doc.Data, want) {
+			t.Fatalf("protocol %d: decoded %#v", proto, doc.Data)
+		}
+		if out.Name != "r1fs" || out.Count != 3 || len(out.Nested.K) != 2 {
+			t.Fatalf("protocol %d: unexpected out %+v", proto, out)
+		}
+	}
+}
+
+func TestGetPickleRejectsExpandingConversion(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+	ctx := context.Background()
+
+	// a = []
+	// for _ in range(26): a = [a, a]
+	// pickle.dumps(a, 4)
+	raw, _ := hex.DecodeString("8004959f000000000000005d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94681a65681965681865681765681665681565681465681365681265681165681065680f65680e65680d65680c65680b65680a656809656808656807656806656805656804656803656802656801652e")
+	cid, err := client.AddFile(ctx, bytes.NewReader(raw), &r1fs.DataOptions{Filename: "obj.pkl"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	if _, err := client.GetPickle(ctx, cid, "", nil); err != nil {
+		t.Fatalf("GetPickle: %v", err)
+	}
+	var out []any
+	if _, err := client.GetPickle(ctx, cid, "", &out); err == nil || !strings.Contains(err.Error(), "elements") {
+		t.Fatalf("expected expansion to be refused, got %v", err)
+	}
+}
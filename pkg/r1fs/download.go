@@ -0,0 +1,345 @@
+package r1fs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Ratio1/edge_sdk_go/internal/httpx"
+)
+
+// OpenBackend is an optional Backend extension for backends that can return
+// file content as a stream. Client.Open and Client.GetFileTo prefer it over
+// Backend.GetFile and Backend.GetFileBase64 when available.
+type OpenBackend interface {
+	OpenFile(ctx context.Context, cid string, secret string) (io.ReadCloser, *FileInfo, error)
+}
+
+// maxLookupPrefix bounds how much of a get_file_base64 response is buffered
+// while looking for the payload field.
+const maxLookupPrefix = 1 << 20
+
+// Open returns a reader over the content of cid. When the node shares this
+// host (see Client.AllowLocalReads) and the path reported by /get_file holds
+// content matching cid, the file is read directly; otherwise the
+// /get_file_base64 payload is decoded as it arrives. The caller must close the
+// returned reader. With StrictNotFound set, missing CIDs are reported with an
+// error wrapping ErrNotFound.
+// With VerifyContent enabled a mismatch surfaces as an IntegrityError from the
+// final Read instead of io.EOF.
+func (c *Client) Open(ctx context.Context, cid string, secret string) (rc io.ReadCloser, info *FileInfo, err error) {
+	if strings.TrimSpace(cid) == "" {
+		return nil, nil, fmt.Errorf("r1fs: cid is required")
+	}
+	if c == nil || c.backend == nil {
+		return nil, nil, fmt.Errorf("r1fs: client is nil")
+	}
+	rc, info, err = c.open(ctx, cid, secret)
+	if err != nil || !c.VerifyContent {
+		return rc, info, err
+	}
+	verified, err := newVerifyingReader(rc, cid, secret)
+	if err != nil {
+		rc.Close()
+		return nil, nil, err
+	}
+	return verified, info, nil
+}
+
+func (c *Client) open(ctx context.Context, cid string, secret string) (io.ReadCloser, *FileInfo, error) {
+	// Content stored with a secret cannot be checked against its CID, so it
+	// is never read from a local path.
+	if c.localReads() && strings.TrimSpace(secret) == "" {
+		loc, err := c.backend.GetFile(ctx, cid, secret)
+		if err != nil {
+			return nil, nil, c.classifyError(cid, err)
+		}
+		if rc, info, ok := openLocal(cid, loc); ok {
+			return rc, info, nil
+		}
+	}
+	if ob, ok := c.backend.(OpenBackend); ok {
+		rc, info, err := ob.OpenFile(ctx, cid, secret)
+		if err != nil {
+			return nil, nil, c.classifyError(cid, err)
+		}
+		return rc, info, nil
+	}
+
+	data, filename, err := c.backend.GetFileBase64(ctx, cid, secret)
+	if err != nil {
+		return nil, nil, c.classifyError(cid, err)
+	}
+	info := &FileInfo{CID: cid, Filename: filename, Size: int64(len(data))}
+	return io.NopCloser(bytes.NewReader(data)), info, nil
+}
+
+// GetFileTo copies the content of cid into w without holding it in memory.
+// The returned FileInfo reports the number of bytes written in Size. When
+// VerifyContent reports an IntegrityError, w has already received the content
+// and should be discarded.
+func (c *Client) GetFileTo(ctx context.Context, cid string, secret string, w io.Writer) (info *FileInfo, err error) {
+	if w == nil {
+		return nil, fmt.Errorf("r1fs: writer is required")
+	}
+	rc, info, err := c.Open(ctx, cid, secret)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	n, err := io.Copy(w, rc)
+	if errors.Is(err, ErrIntegrity) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("r1fs: copy cid %s: %w", cid, err)
+	}
+	info.Size = n
+	return info, nil
+}
+
+// OpenFile implements OpenBackend for the HTTP backend. The filename is taken
+// from the same /get_file_base64 response; when the node sends it after the
+// payload, info.Filename is filled in once the reader reaches EOF.
+func (b *httpBackend) OpenFile(ctx context.Context, cid string, secret string) (io.ReadCloser, *FileInfo, error) {
+	if b == nil || b.client == nil {
+		return nil, nil, fmt.Errorf("r1fs: http backend not configured")
+	}
+	query := map[string]any{"cid": cid}
+	if strings.TrimSpace(secret) != "" {
+		query["secret"] = secret
+	}
+	body, err := encodeJSON(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := b.client.Do(ctx, &httpx.Request{
+		Method:      http.MethodPost,
+		Path:        "get_file_base64",
+		Idempotency: httpx.Idempotent,
+		Header:      http.Header{"Content-Type": []string{"application/json"}},
+		Body:        bytes.NewReader(body),
+		GetBody: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	info := &FileInfo{CID: cid, Size: -1}
+	payload, err := newBase64FieldReader(resp.Body, "file_base64_str")
+	if err != nil {
+		resp.Body.Close()
+		if errors.Is(err, errFieldMissing) {
+			var result struct {
+				FileBase64 string `json:"file_base64_str"`
+				Filename   string `json:"filename"`
+			}
+			if err := decodeLookupResult(payload.prefix, "get_file_base64", cid, &result); err != nil {
+				return nil, nil, err
+			}
+			// An empty result with no payload field is an empty file.
+			info.Filename, info.Size = result.Filename, 0
+			return io.NopCloser(bytes.NewReader(nil)), info, nil
+		}
+		return nil, nil, fmt.Errorf("r1fs: read get_file_base64 response: %w", err)
+	}
+	payload.complete = func(doc []byte) {
+		var result struct {
+			Filename string `json:"filename"`
+		}
+		if decodeLookupResult(doc, "get_file_base64", cid, &result) == nil {
+			info.Filename = result.Filename
+		}
+	}
+	return payload, info, nil
+}
+
+// localReads reports whether paths returned by /get_file may be read from
+// this host.
+func (c *Client) localReads() bool {
+	if c.AllowLocalReads {
+		return true
+	}
+	hb, ok := c.backend.(*httpBackend)
+	return ok && hb.client.IsLocal()
+}
+
+// openLocal opens the path reported by /get_file when it is a regular file on
+// this host whose content hashes to cid. A node is free to name any path, so
+// content that does not match is never returned.
+func openLocal(cid string, loc *FileLocation) (io.ReadCloser, *FileInfo, bool) {
+	if loc == nil || strings.TrimSpace(loc.Path) == "" {
+		return nil, nil, false
+	}
+	f, err := os.Open(loc.Path)
+	if err != nil {
+		return nil, nil, false
+	}
+	st, err := f.Stat()
+	if err != nil || !st.Mode().IsRegular() {
+		f.Close()
+		return nil, nil, false
+	}
+	if err := verifyReader(cid, "", f); err != nil {
+		f.Close()
+		return nil, nil, false
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, nil, false
+	}
+	return f, &FileInfo{CID: cid, Filename: loc.Filename, Path: loc.Path, Size: st.Size()}, true
+}
+
+var errFieldMissing = errors.New("field not found")
+
+// base64FieldReader decodes a base64 string field of a JSON response while
+// the response is still being received. The field may sit in a plain object or
+// in the JSON-encoded string the upstream sometimes wraps results in.
+type base64FieldReader struct {
+	body    io.Closer
+	src     *bufio.Reader
+	decoder io.Reader
+	done    bool
+	// prefix holds the response bytes read before the field value, so that a
+	// response without the field can still be decoded as a regular result.
+	prefix []byte
+	// complete, when set, receives the response with the field value emptied
+	// once the value has been read, so the other fields can be decoded.
+	complete func(doc []byte)
+}
+
+func newBase64FieldReader(body io.ReadCloser, field string) (*base64FieldReader, error) {
+	r := &base64FieldReader{body: body, src: bufio.NewReader(body)}
+	keys := [][]byte{[]byte(`"` + field + `"`), []byte(`\"` + field + `\"`)}
+	for {
+		ch, err := r.src.ReadByte()
+		if err == io.EOF {
+			return r, errFieldMissing
+		}
+		if err != nil {
+			return r, err
+		}
+		r.prefix = append(r.prefix, ch)
+		if len(r.prefix) > maxLookupPrefix {
+			return r, fmt.Errorf("no %q field in the first %d bytes", field, maxLookupPrefix)
+		}
+		if !bytes.HasSuffix(r.prefix, keys[0]) && !bytes.HasSuffix(r.prefix, keys[1]) {
+			continue
+		}
+		if err := r.openValue(); err != nil {
+			if errors.Is(err, errFieldMissing) {
+				continue
+			}
+			return r, err
+		}
+		r.decoder = base64.NewDecoder(base64.StdEncoding, readerFunc(r.readRaw))
+		return r, nil
+	}
+}
+
+// openValue consumes the `: "` (or `: \"`) separating a key from its value.
+func (r *base64FieldReader) openValue() error {
+	seenColon := false
+	for {
+		ch, err := r.src.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return errFieldMissing
+			}
+			return err
+		}
+		r.prefix = append(r.prefix, ch)
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+		case ch == ':' && !seenColon:
+			seenColon = true
+		case ch == '\\' && seenColon:
+		case ch == '"' && seenColon:
+			return nil
+		default:
+			return errFieldMissing
+		}
+	}
+}
+
+// readRaw returns the raw base64 characters of the value, stopping at the
+// closing quote and unescaping `\/`.
+func (r *base64FieldReader) readRaw(p []byte) (int, error) {
+	if r.done {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) {
+		ch, err := r.src.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return n, err
+		}
+		switch ch {
+		case '"':
+			r.finish(`"`)
+			return n, io.EOF
+		case '\\':
+			next, err := r.src.ReadByte()
+			if err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return n, err
+			}
+			if next != '/' {
+				// `\"` closes a value nested in a JSON-encoded string.
+				r.finish(string([]byte{ch, next}))
+				return n, io.EOF
+			}
+			p[n] = '/'
+		default:
+			p[n] = ch
+		}
+		n++
+		if r.src.Buffered() == 0 {
+			// Hand over what we have rather than block on the network.
+			return n, nil
+		}
+	}
+	return n, nil
+}
+
+// finish marks the value as read and hands the rest of the response, joined to
+// the prefix by the closing quote, to complete.
+func (r *base64FieldReader) finish(closing string) {
+	r.done = true
+	if r.complete == nil {
+		return
+	}
+	rest, err := io.ReadAll(io.LimitReader(r.src, maxLookupPrefix))
+	if err != nil {
+		return
+	}
+	doc := make([]byte, 0, len(r.prefix)+len(closing)+len(rest))
+	doc = append(append(append(doc, r.prefix...), closing...), rest...)
+	r.complete(doc)
+}
+
+func (r *base64FieldReader) Read(p []byte) (int, error) {
+	return r.decoder.Read(p)
+}
+
+func (r *base64FieldReader) Close() error {
+	return r.body.Close()
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
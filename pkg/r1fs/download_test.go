@@ -0,0 +1,249 @@
+package r1fs_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/cid"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/memfs"
+)
+
+func newDownloadServer(t *testing.T, filePath string, base64Body func(w http.ResponseWriter)) *testServer {
+	t.Helper()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/get_file":
+			if r.URL.Query().Get("cid") == "QmMissing" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"result": "error"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{
+				"file_path": filePath,
+				"meta":      map[string]any{"filename": "artifact.bin"},
+			}})
+		case "/get_file_base64":
+			var query struct {
+				CID string `json:"cid"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&query)
+			if query.CID == "QmMissing" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"result": "error"})
+				return
+			}
+			if base64Body == nil {
+				t.Errorf("unexpected get_file_base64 request")
+				http.Error(w, "unexpected", http.StatusInternalServerError)
+				return
+			}
+			base64Body(w)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	return newLocalHTTPServer(t, handler)
+}
+
+func TestGetFileToStreamsBase64(t *testing.T) {
+	payload := bytes.Repeat([]byte{0x00, 0xff, 0x10, 0x7f, 0x3e}, 200_001)
+	encoded := base64.StdEncoding.EncodeToString(payload)
+	srv := newDownloadServer(t, "/edge_node/_local_cache/missing.bin", func(w http.ResponseWriter) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{
+			"file_base64_str": encoded,
+			"filename":        "artifact.bin",
+		}})
+	})
+	defer srv.Close()
+	client, err := r1fs.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var out bytes.Buffer
+	info, err := client.GetFileTo(context.Background(), "QmDownload", "", &out)
+	if err != nil {
+		t.Fatalf("GetFileTo: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), payload) {
+		t.Fatalf("downloaded %d bytes, want %d", out.Len(), len(payload))
+	}
+	if info.Size != int64(len(payload)) || info.Filename != "artifact.bin" || info.Path != "" {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+}
+
+func TestOpenDecodesJSONEncodedResult(t *testing.T) {
+	payload := []byte("????>>>>streamed payload")
+	encoded := base64.StdEncoding.EncodeToString(payload)
+	inner := `{"filename": "artifact.bin", "file_base64_str": "` + encoded + `"}`
+	srv := newDownloadServer(t, "", func(w http.ResponseWriter) {
+		// Mimic a result wrapped in a JSON string with escaped slashes.
+		quoted := strconv.Quote(inner)
+		quoted = string(bytes.ReplaceAll([]byte(quoted), []byte("/"), []byte(`\/`)))
+		_, _ = io.WriteString(w, `{"result": `+quoted+`}`)
+	})
+	defer srv.Close()
+	client, err := r1fs.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	rc, info, err := client.Open(context.Background(), "QmDownload", "")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("unexpected payload %q", got)
+	}
+	if info.Size != -1 || info.Filename != "artifact.bin" {
+		t.Fatalf("unexpected info for streamed download: %+v", info)
+	}
+}
+
+func TestOpenSkipsGetFileWhenStreaming(t *testing.T) {
+	var getFile atomic.Int32
+	srv := newLocalHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/get_file":
+			getFile.Add(1)
+			http.Error(w, "unexpected", http.StatusInternalServerError)
+		case "/get_file_base64":
+			// The filename follows the payload, as json.Encoder sorts keys.
+			_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{
+				"file_base64_str": base64.StdEncoding.EncodeToString([]byte("remote")),
+				"filename":        "remote.txt",
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	client, err := r1fs.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// Content stored with a secret is never read locally, so Open goes
+	// straight to /get_file_base64.
+	var out bytes.Buffer
+	info, err := client.GetFileTo(context.Background(), "QmRemote", "secret", &out)
+	if err != nil {
+		t.Fatalf("GetFileTo: %v", err)
+	}
+	if out.String() != "remote" || info.Filename != "remote.txt" {
+		t.Fatalf("unexpected download: %q %+v", out.String(), info)
+	}
+	if n := getFile.Load(); n != 0 {
+		t.Fatalf("get_file called %d times", n)
+	}
+}
+
+func TestOpenReadsLocalPath(t *testing.T) {
+	content := []byte("local bytes")
+	id, err := cid.SumBytes(content, nil)
+	if err != nil {
+		t.Fatalf("SumBytes: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "artifact.bin")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	srv := newDownloadServer(t, path, nil)
+	defer srv.Close()
+	client, err := r1fs.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var out bytes.Buffer
+	info, err := client.GetFileTo(context.Background(), id.String(), "", &out)
+	if err != nil {
+		t.Fatalf("GetFileTo: %v", err)
+	}
+	if out.String() != "local bytes" || info.Path != path {
+		t.Fatalf("unexpected local read: %q %+v", out.String(), info)
+	}
+}
+
+func TestOpenIgnoresLocalPathWithOtherContent(t *testing.T) {
+	remote := []byte("remote bytes")
+	id, err := cid.SumBytes(remote, nil)
+	if err != nil {
+		t.Fatalf("SumBytes: %v", err)
+	}
+	// The node names a path that holds something else on this host.
+	path := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(path, []byte("host secret"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	srv := newDownloadServer(t, path, func(w http.ResponseWriter) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{
+			"file_base64_str": base64.StdEncoding.EncodeToString(remote),
+		}})
+	})
+	defer srv.Close()
+	client, err := r1fs.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var out bytes.Buffer
+	info, err := client.GetFileTo(context.Background(), id.String(), "", &out)
+	if err != nil {
+		t.Fatalf("GetFileTo: %v", err)
+	}
+	if out.String() != "remote bytes" || info.Path != "" {
+		t.Fatalf("expected the streamed payload, got %q %+v", out.String(), info)
+	}
+}
+
+func TestOpenMissingCID(t *testing.T) {
+	srv := newDownloadServer(t, "", nil)
+	defer srv.Close()
+	client, err := r1fs.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	client.StrictNotFound = true
+
+	if _, _, err := client.Open(context.Background(), "QmMissing", ""); !errors.Is(err, r1fs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestOpenWithBackend(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+
+	ctx := context.Background()
+	cid, err := client.AddFile(ctx, bytes.NewReader([]byte("in memory")), &r1fs.DataOptions{Filename: "mem.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	var out bytes.Buffer
+	info, err := client.GetFileTo(ctx, cid, "", &out)
+	if err != nil {
+		t.Fatalf("GetFileTo: %v", err)
+	}
+	if out.String() != "in memory" || info.Filename != "mem.txt" {
+		t.Fatalf("unexpected download: %q %+v", out.String(), info)
+	}
+}
@@ -0,0 +1,338 @@
+package r1fs
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/pickle"
+)
+
+// KeyProvider supplies the AES keys used by EncryptingBackend. Keys must be
+// 16, 24 or 32 bytes long.
+type KeyProvider interface {
+	// EncryptionKey returns the key that seals new payloads and its ID.
+	EncryptionKey(ctx context.Context) (keyID string, key []byte, err error)
+	// DecryptionKey returns the key identified by keyID.
+	DecryptionKey(ctx context.Context, keyID string) (key []byte, err error)
+}
+
+// StaticKeys is a KeyProvider over a fixed key set. Payloads are sealed with
+// Keys[Current]; the remaining keys stay available for reading content sealed
+// before a rotation.
+type StaticKeys struct {
+	Current string
+	Keys    map[string][]byte
+}
+
+// NewStaticKeys returns a StaticKeys holding a single key.
+func NewStaticKeys(keyID string, key []byte) *StaticKeys {
+	return &StaticKeys{Current: keyID, Keys: map[string][]byte{keyID: key}}
+}
+
+// EncryptionKey returns Keys[Current].
+func (k *StaticKeys) EncryptionKey(ctx context.Context) (string, []byte, error) {
+	key, err := k.DecryptionKey(ctx, k.Current)
+	if err != nil {
+		return "", nil, err
+	}
+	return k.Current, key, nil
+}
+
+// DecryptionKey returns Keys[keyID].
+func (k *StaticKeys) DecryptionKey(ctx context.Context, keyID string) ([]byte, error) {
+	key, ok := k.Keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("r1fs: unknown key id %q", keyID)
+	}
+	return key, nil
+}
+
+// Sealed payloads start with a header that is authenticated together with
+// the ciphertext:
+//
+//	magic "R1FE" | version (1) | key ID length (1 byte) | key ID | 12-byte nonce
+const (
+	sealMagic   = "R1FE"
+	sealVersion = 1
+)
+
+// EncryptingBackend wraps a Backend and encrypts every payload with AES-GCM
+// before it leaves the process, so the node only stores ciphertext. Files,
+// JSON, YAML and pickle documents are all uploaded as sealed files and opened
+// transparently by GetFileBase64, GetFile, GetYAML and OpenFile, which makes
+// Client.GetJSON and Client.GetPickle work unchanged.
+//
+// CIDs address the ciphertext, which uses a random nonce: CalculateJSONCID and
+// CalculatePickleCID are not supported and Client.VerifyContent must stay
+// disabled. DataOptions.Secret is still forwarded to the wrapped backend.
+// Uploads are buffered in memory.
+type EncryptingBackend struct {
+	inner Backend
+	keys  KeyProvider
+
+	// AllowPlaintext returns payloads without a sealed header unchanged
+	// instead of failing with ErrDecrypt, easing migration of existing
+	// content.
+	AllowPlaintext bool
+
+	mu     sync.Mutex
+	tmpDir string
+}
+
+// NewEncryptingBackend wraps inner with client-side encryption using keys.
+func NewEncryptingBackend(inner Backend, keys KeyProvider) *EncryptingBackend {
+	return &EncryptingBackend{inner: inner, keys: keys}
+}
+
+// Close removes files decrypted by GetFile.
+func (b *EncryptingBackend) Close() error {
+	b.mu.Lock()
+	dir := b.tmpDir
+	b.tmpDir = ""
+	b.mu.Unlock()
+	if dir == "" {
+		return nil
+	}
+	return os.RemoveAll(dir)
+}
+
+// Seal encrypts data with the current key of the provider.
+func (b *EncryptingBackend) Seal(ctx context.Context, data []byte) ([]byte, error) {
+	keyID, key, err := b.keys.EncryptionKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyID) > 255 {
+		return nil, fmt.Errorf("r1fs: key id %q is longer than 255 bytes", keyID)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 0, len(sealMagic)+2+len(keyID)+aead.NonceSize())
+	header = append(header, sealMagic...)
+	header = append(header, sealVersion, byte(len(keyID)))
+	header = append(header, keyID...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("r1fs: generate nonce: %w", err)
+	}
+	header = append(header, nonce...)
+	return aead.Seal(header, nonce, data, header), nil
+}
+
+// Open decrypts a payload produced by Seal. Errors wrap ErrDecrypt.
+func (b *EncryptingBackend) Open(ctx context.Context, sealed []byte) ([]byte, error) {
+	if !bytes.HasPrefix(sealed, []byte(sealMagic)) {
+		if b.AllowPlaintext {
+			return sealed, nil
+		}
+		return nil, fmt.Errorf("%w: missing header", ErrDecrypt)
+	}
+	rest := sealed[len(sealMagic):]
+	if len(rest) < 2 || rest[0] != sealVersion {
+		return nil, fmt.Errorf("%w: unsupported header", ErrDecrypt)
+	}
+	idLen := int(rest[1])
+	rest = rest[2:]
+	if len(rest) < idLen {
+		return nil, fmt.Errorf("%w: truncated header", ErrDecrypt)
+	}
+	keyID := string(rest[:idLen])
+	rest = rest[idLen:]
+	key, err := b.keys.DecryptionKey(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, err)
+	}
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("%w: truncated payload", ErrDecrypt)
+	}
+	headerLen := len(sealed) - len(rest) + aead.NonceSize()
+	nonce := rest[:aead.NonceSize()]
+	plain, err := aead.Open(nil, nonce, sealed[headerLen:], sealed[:headerLen])
+	if err != nil {
+		return nil, fmt.Errorf("%w: key %q: %w", ErrDecrypt, keyID, err)
+	}
+	return plain, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("r1fs: encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (b *EncryptingBackend) AddFileBase64(ctx context.Context, data []byte, opts *DataOptions) (cid string, err error) {
+	sealed, err := b.Seal(ctx, data)
+	if err != nil {
+		return "", err
+	}
+	return b.inner.AddFileBase64(ctx, sealed, opts)
+}
+
+func (b *EncryptingBackend) AddFile(ctx context.Context, data []byte, opts *DataOptions) (cid string, err error) {
+	sealed, err := b.Seal(ctx, data)
+	if err != nil {
+		return "", err
+	}
+	return b.inner.AddFile(ctx, sealed, opts)
+}
+
+func (b *EncryptingBackend) GetFileBase64(ctx context.Context, cid string, secret string) (fileData []byte, fileName string, err error) {
+	sealed, fileName, err := b.inner.GetFileBase64(ctx, cid, secret)
+	if err != nil {
+		return nil, "", err
+	}
+	plain, err := b.Open(ctx, sealed)
+	if err != nil {
+		return nil, "", fmt.Errorf("r1fs: cid %s: %w", cid, err)
+	}
+	return plain, fileName, nil
+}
+
+// GetFile decrypts the file into a private temporary directory and reports
+// that location. Call Close to remove decrypted files.
+func (b *EncryptingBackend) GetFile(ctx context.Context, cid string, secret string) (location *FileLocation, err error) {
+	data, fileName, err := b.GetFileBase64(ctx, cid, secret)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := b.plainDir()
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(fileName)
+	if name == "." || name == string(filepath.Separator) || strings.TrimSpace(name) == "" {
+		name = "file"
+	}
+	// The cid comes from the caller and may contain separators or "..", so
+	// it is hashed into the directory name.
+	sum := sha256.Sum256([]byte(cid))
+	target := filepath.Join(dir, hex.EncodeToString(sum[:16]), name)
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		return nil, fmt.Errorf("r1fs: write decrypted file: %w", err)
+	}
+	if err := os.WriteFile(target, data, 0o600); err != nil {
+		return nil, fmt.Errorf("r1fs: write decrypted file: %w", err)
+	}
+	return &FileLocation{Path: target, Filename: fileName}, nil
+}
+
+// OpenFile implements OpenBackend so Client.Open never reads ciphertext from
+// a path shared with the node.
+func (b *EncryptingBackend) OpenFile(ctx context.Context, cid string, secret string) (io.ReadCloser, *FileInfo, error) {
+	data, fileName, err := b.GetFileBase64(ctx, cid, secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	info := &FileInfo{CID: cid, Filename: fileName, Size: int64(len(data))}
+	return io.NopCloser(bytes.NewReader(data)), info, nil
+}
+
+func (b *EncryptingBackend) DeleteFile(ctx context.Context, cid string, opts *DeleteOptions) (*DeleteFileResult, error) {
+	return b.inner.DeleteFile(ctx, cid, opts)
+}
+
+func (b *EncryptingBackend) DeleteFiles(ctx context.Context, cids []string, opts *DeleteOptions) (*DeleteFilesResult, error) {
+	return b.inner.DeleteFiles(ctx, cids, opts)
+}
+
+// AddJSON seals the JSON encoding of data and uploads it as a file.
+func (b *EncryptingBackend) AddJSON(ctx context.Context, data any, opts *DataOptions) (cid string, err error) {
+	payload, err := encodeJSON(data)
+	if err != nil {
+		return "", fmt.Errorf("r1fs: encode JSON payload: %w", err)
+	}
+	return b.addDocument(ctx, payload, "data.json", opts)
+}
+
+// AddPickle pickles data locally, seals it and uploads it as a file.
+func (b *EncryptingBackend) AddPickle(ctx context.Context, data any, opts *DataOptions) (cid string, err error) {
+	doc, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("r1fs: encode pickle payload: %w", err)
+	}
+	payload, err := pickle.MarshalJSON(doc)
+	if err != nil {
+		return "", fmt.Errorf("r1fs: %w", err)
+	}
+	return b.addDocument(ctx, payload, "data.pkl", opts)
+}
+
+// AddYAML seals data as JSON, which GetYAML returns in the shape produced by
+// the node's /get_yaml.
+func (b *EncryptingBackend) AddYAML(ctx context.Context, data any, opts *DataOptions) (cid string, err error) {
+	payload, err := encodeJSON(data)
+	if err != nil {
+		return "", fmt.Errorf("r1fs: encode YAML payload: %w", err)
+	}
+	return b.addDocument(ctx, payload, "data.yaml", opts)
+}
+
+func (b *EncryptingBackend) GetYAML(ctx context.Context, cid string, secret string) (payload []byte, err error) {
+	data, _, err := b.GetFileBase64(ctx, cid, secret)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("r1fs: cid %s does not hold a document written by EncryptingBackend.AddYAML", cid)
+	}
+	return json.Marshal(map[string]json.RawMessage{"file_data": data})
+}
+
+func (b *EncryptingBackend) CalculateJSONCID(ctx context.Context, data any, nonce int, opts *DataOptions) (cid string, err error) {
+	return "", fmt.Errorf("r1fs: CIDs of client-side encrypted payloads cannot be calculated")
+}
+
+func (b *EncryptingBackend) CalculatePickleCID(ctx context.Context, data any, nonce int, opts *DataOptions) (cid string, err error) {
+	return "", fmt.Errorf("r1fs: CIDs of client-side encrypted payloads cannot be calculated")
+}
+
+func (b *EncryptingBackend) addDocument(ctx context.Context, payload []byte, defaultName string, opts *DataOptions) (string, error) {
+	fileOpts := &DataOptions{Filename: defaultName}
+	if opts != nil {
+		copied := *opts
+		fileOpts = &copied
+		if strings.TrimSpace(fileOpts.Filename) == "" && strings.TrimSpace(fileOpts.FilePath) == "" {
+			fileOpts.Filename = defaultName
+		}
+	}
+	return b.AddFile(ctx, payload, fileOpts)
+}
+
+func (b *EncryptingBackend) plainDir() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tmpDir != "" {
+		return b.tmpDir, nil
+	}
+	dir, err := os.MkdirTemp("", "r1fs-plain-")
+	if err != nil {
+		return "", fmt.Errorf("r1fs: create temp dir: %w", err)
+	}
+	b.tmpDir = dir
+	return dir, nil
+}
+
+var (
+	_ Backend     = (*EncryptingBackend)(nil)
+	_ OpenBackend = (*EncryptingBackend)(nil)
+)
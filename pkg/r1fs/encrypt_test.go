@@ -0,0 +1,163 @@
+package r1fs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/memfs"
+)
+
+func TestEncryptingBackendRoundTrip(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	keys := r1fs.NewStaticKeys("k1", bytes.Repeat([]byte{1}, 32))
+	backend := r1fs.NewEncryptingBackend(store, keys)
+	defer backend.Close()
+	client := r1fs.NewWithBackend(backend)
+	ctx := context.Background()
+
+	cid, err := client.AddFile(ctx, strings.NewReader("top secret"), &r1fs.DataOptions{Filename: "secret.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	stored, _, err := store.GetFileBase64(ctx, cid, "")
+	if err != nil {
+		t.Fatalf("read ciphertext: %v", err)
+	}
+	if bytes.Contains(stored, []byte("top secret")) || !bytes.HasPrefix(stored, []byte("R1FE")) {
+		t.Fatalf("node received plaintext: %q", stored)
+	}
+
+	data, name, err := client.GetFileBase64(ctx, cid, "")
+	if err != nil || string(data) != "top secret" || name != "secret.txt" {
+		t.Fatalf("GetFileBase64: %q %q err=%v", data, name, err)
+	}
+	loc, err := client.GetFile(ctx, cid, "")
+	if err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+	if onDisk, err := os.ReadFile(loc.Path); err != nil || string(onDisk) != "top secret" {
+		t.Fatalf("GetFile content: %q err=%v", onDisk, err)
+	}
+	rc, _, err := client.Open(ctx, cid, "")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	streamed, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(streamed) != "top secret" {
+		t.Fatalf("Open content: %q", streamed)
+	}
+
+	type config struct {
+		Name string `json:"name"`
+	}
+	jsonCID, err := client.AddJSON(ctx, config{Name: "ratio1"}, nil)
+	if err != nil {
+		t.Fatalf("AddJSON: %v", err)
+	}
+	doc, err := r1fs.GetJSONAs[config](ctx, client, jsonCID, "")
+	if err != nil || doc.Data.Name != "ratio1" {
+		t.Fatalf("GetJSONAs: %#v err=%v", doc, err)
+	}
+	yamlCID, err := client.AddYAML(ctx, config{Name: "yaml"}, nil)
+	if err != nil {
+		t.Fatalf("AddYAML: %v", err)
+	}
+	var fromYAML config
+	if _, err := client.GetYAML(ctx, yamlCID, "", &fromYAML); err != nil || fromYAML.Name != "yaml" {
+		t.Fatalf("GetYAML: %#v err=%v", fromYAML, err)
+	}
+	pickleCID, err := client.AddPickle(ctx, map[string]any{"n": 3}, nil)
+	if err != nil {
+		t.Fatalf("AddPickle: %v", err)
+	}
+	obj, err := client.GetPickle(ctx, pickleCID, "", nil)
+	if err != nil || obj.Data.(map[string]any)["n"] != int64(3) {
+		t.Fatalf("GetPickle: %#v err=%v", obj, err)
+	}
+
+	// Rotating keys keeps older content readable.
+	keys.Keys["k2"] = bytes.Repeat([]byte{2}, 16)
+	keys.Current = "k2"
+	if data, _, err := client.GetFileBase64(ctx, cid, ""); err != nil || string(data) != "top secret" {
+		t.Fatalf("read after rotation: %q err=%v", data, err)
+	}
+	delete(keys.Keys, "k1")
+	if _, _, err := client.GetFileBase64(ctx, cid, ""); !errors.Is(err, r1fs.ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt for a removed key, got %v", err)
+	}
+}
+
+func TestEncryptingBackendRejectsPlaintext(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	ctx := context.Background()
+	cid, err := store.AddFile(ctx, []byte("legacy"), &r1fs.DataOptions{Filename: "legacy.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+
+	backend := r1fs.NewEncryptingBackend(store, r1fs.NewStaticKeys("k", bytes.Repeat([]byte{7}, 32)))
+	client := r1fs.NewWithBackend(backend)
+	if _, _, err := client.GetFileBase64(ctx, cid, ""); !errors.Is(err, r1fs.ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+	backend.AllowPlaintext = true
+	if data, _, err := client.GetFileBase64(ctx, cid, ""); err != nil || string(data) != "legacy" {
+		t.Fatalf("AllowPlaintext: %q err=%v", data, err)
+	}
+
+	sealed, err := backend.Seal(ctx, []byte("payload"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, err := backend.Open(ctx, sealed); !errors.Is(err, r1fs.ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt for tampered ciphertext, got %v", err)
+	}
+}
+
+// aliasStore serves the file stored under target for any requested cid.
+type aliasStore struct {
+	*memfs.Store
+	target string
+}
+
+func (s *aliasStore) GetFileBase64(ctx context.Context, _ string, secret string) ([]byte, string, error) {
+	return s.Store.GetFileBase64(ctx, s.target, secret)
+}
+
+func TestEncryptingBackendGetFileStaysInTempDir(t *testing.T) {
+	store := &aliasStore{Store: memfs.New()}
+	defer store.Close()
+	backend := r1fs.NewEncryptingBackend(store, r1fs.NewStaticKeys("k1", bytes.Repeat([]byte{1}, 32)))
+	defer backend.Close()
+	ctx := context.Background()
+
+	cid, err := backend.AddFile(ctx, []byte("plain"), &r1fs.DataOptions{Filename: "a.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	store.target = cid
+	safe, err := backend.GetFile(ctx, cid, "")
+	if err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+	root := filepath.Dir(filepath.Dir(safe.Path))
+
+	loc, err := backend.GetFile(ctx, "../../escape", "")
+	if err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+	if rel, err := filepath.Rel(root, loc.Path); err != nil || strings.HasPrefix(rel, "..") {
+		t.Fatalf("decrypted file written outside %s: %s", root, loc.Path)
+	}
+}
@@ -2,11 +2,24 @@ package r1fs_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1transport"
 )
 
 func TestNewFromEnvHTTP(t *testing.T) {
@@ -52,3 +65,144 @@ func TestNewFromEnvInvalidURL(t *testing.T) {
 		t.Fatalf("expected error for invalid URL")
 	}
 }
+
+func TestNewFromEnvMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newTestCert(t, nil, nil, "r1-test-ca", true)
+	serverCert, serverKey := newTestCert(t, ca, caKey, "node.r1.internal", false)
+	clientCert, clientKey := newTestCert(t, ca, caKey, "edge-client", false)
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", ca.Raw)
+	writePEM(t, filepath.Join(dir, "client.pem"), "CERTIFICATE", clientCert.Raw)
+	writePEM(t, filepath.Join(dir, "client.key"), "PRIVATE KEY", marshalKey(t, clientKey))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("network disabled for tests: %v", err)
+	}
+	tlsLn := tls.NewListener(ln, &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS12,
+	})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "edge-client" {
+			t.Errorf("unexpected peer certificates")
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]string{"file_base64_str": "", "filename": "empty"}})
+	})}
+	go func() { _ = srv.Serve(tlsLn) }()
+	defer srv.Close()
+
+	t.Setenv("EE_R1FS_API_URL", "https://"+ln.Addr().String())
+	t.Setenv("EE_R1_TLS_CA", filepath.Join(dir, "ca.pem"))
+	t.Setenv("EE_R1_TLS_SERVER_NAME", "node.r1.internal")
+	t.Setenv("EE_R1_TLS_MIN_VERSION", "1.2")
+	noRetry := r1transport.WithRetryPolicy(r1transport.RetryPolicy{MaxRetries: 0})
+
+	// Without a client certificate the handshake is rejected.
+	client, err := r1fs.NewFromEnv(noRetry)
+	if err != nil {
+		t.Fatalf("NewFromEnv: %v", err)
+	}
+	if _, _, err := client.GetFileBase64(context.Background(), "QmTLS", ""); err == nil {
+		t.Fatalf("expected the server to require a client certificate")
+	}
+
+	t.Setenv("EE_R1_TLS_CERT", filepath.Join(dir, "client.pem"))
+	t.Setenv("EE_R1_TLS_KEY", filepath.Join(dir, "client.key"))
+	client, err = r1fs.NewFromEnv(noRetry)
+	if err != nil {
+		t.Fatalf("NewFromEnv: %v", err)
+	}
+	if _, _, err := client.GetFileBase64(context.Background(), "QmTLS", ""); err != nil {
+		t.Fatalf("GetFileBase64 over mTLS: %v", err)
+	}
+
+	t.Setenv("EE_R1_TLS_KEY", "")
+	if _, err := r1fs.NewFromEnv(); err == nil {
+		t.Fatalf("expected an error for a certificate without a key")
+	}
+}
+
+func newTestCert(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, name string, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return cert, key
+}
+
+func marshalKey(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return der
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestNewFromEnvUnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "r1fs.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	var paths []string
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]string{"file_base64_str": "aGk=", "filename": "hi.txt"}})
+	})}
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Close()
+
+	t.Setenv("EE_R1FS_API_URL", "unix://"+sock)
+	client, err := r1fs.NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv: %v", err)
+	}
+	data, _, err := client.GetFileBase64(context.Background(), "QmSocket", "")
+	if err != nil || string(data) != "hi" {
+		t.Fatalf("GetFileBase64 over unix socket: %q err=%v", data, err)
+	}
+	if len(paths) != 1 || paths[0] != "/get_file_base64" {
+		t.Fatalf("unexpected request paths %v", paths)
+	}
+
+	t.Setenv("EE_R1FS_API_URL", "unix://relative.sock")
+	if _, err := r1fs.NewFromEnv(); err == nil {
+		t.Fatalf("expected an error for a unix URL without an absolute path")
+	}
+}
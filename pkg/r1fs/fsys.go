@@ -0,0 +1,397 @@
+package r1fs
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultFSCacheSize bounds the file content an FS keeps in memory.
+const DefaultFSCacheSize = 64 << 20
+
+// FS is a read-only fs.FS over a directory manifest written by
+// Client.AddDir. It implements fs.ReadFileFS, fs.StatFS and fs.ReadDirFS, and
+// opened files implement io.Seeker and io.ReaderAt, so it can be handed to
+// http.FS, template.ParseFS and similar helpers.
+//
+// The manifest is fetched on first use. Opening a file does not download it:
+// the whole content is fetched on the first Read or ReadAt, checked against
+// the manifest and kept in an in-memory LRU cache keyed by CID, so Stat and
+// Seek on an open file stay cheap. Large files are better served through
+// Client.OpenLarge. An FS is safe for concurrent use.
+type FS struct {
+	ctx   context.Context
+	state *fsState
+}
+
+type fsState struct {
+	client      *Client
+	manifestCID string
+	secret      string
+
+	mu    sync.Mutex
+	files map[string]DirEntry
+	dirs  map[string][]fs.DirEntry // sorted children of every directory
+	cache *blockCache
+}
+
+// NewFS returns an FS over the manifest stored at manifestCID. Requests are
+// made with context.Background; use WithContext to bound them.
+func NewFS(client *Client, manifestCID string, secret string) *FS {
+	return &FS{
+		ctx: context.Background(),
+		state: &fsState{
+			client:      client,
+			manifestCID: manifestCID,
+			secret:      secret,
+			cache:       newBlockCache(DefaultFSCacheSize),
+		},
+	}
+}
+
+// WithContext returns a view of f that issues requests with ctx. The views
+// share the manifest and cache.
+func (f *FS) WithContext(ctx context.Context) *FS {
+	return &FS{ctx: ctx, state: f.state}
+}
+
+// Open opens the named file or directory. File content is downloaded on the
+// first Read or ReadAt.
+func (f *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if err := f.load(); err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if entry, ok := f.state.files[name]; ok {
+		return &fsFile{fsys: f, entry: entry, info: fileInfoOf(entry)}, nil
+	}
+	if children, ok := f.state.dirs[name]; ok {
+		return &fsDir{info: dirInfo(name), entries: children}, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadFile returns the content of the named file.
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	if err := f.load(); err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	entry, ok := f.state.files[name]
+	if !ok {
+		if _, isDir := f.state.dirs[name]; isDir {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: errIsDir}
+		}
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	data, err := f.content(entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return bytes.Clone(data), nil
+}
+
+// Stat describes the named file from the manifest without downloading it.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	if err := f.load(); err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	if entry, ok := f.state.files[name]; ok {
+		return fileInfoOf(entry), nil
+	}
+	if _, ok := f.state.dirs[name]; ok {
+		return dirInfo(name), nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir lists the named directory sorted by name.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	if err := f.load(); err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	children, ok := f.state.dirs[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]fs.DirEntry(nil), children...), nil
+}
+
+var (
+	errIsDir          = errors.New("is a directory")
+	errNegativeOffset = errors.New("negative offset")
+)
+
+// load fetches and indexes the manifest once. Failures are not cached.
+func (f *FS) load() error {
+	s := f.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.files != nil {
+		return nil
+	}
+	if s.client == nil {
+		return fmt.Errorf("r1fs: client is nil")
+	}
+	manifest, err := s.client.loadDirManifest(f.ctx, s.manifestCID, s.secret)
+	if err != nil {
+		return err
+	}
+	files := make(map[string]DirEntry, len(manifest.Files))
+	dirs := map[string][]fs.DirEntry{".": nil}
+	for _, entry := range manifest.Files {
+		files[entry.Path] = entry
+		child := fs.FileInfoToDirEntry(fileInfoOf(entry))
+		for dir := path.Dir(entry.Path); ; dir = path.Dir(dir) {
+			_, seen := dirs[dir]
+			dirs[dir] = append(dirs[dir], child)
+			if seen || dir == "." {
+				break
+			}
+			child = fs.FileInfoToDirEntry(dirInfo(dir))
+		}
+	}
+	for dir, children := range dirs {
+		sort.Slice(children, func(i, j int) bool { return children[i].Name() < children[j].Name() })
+		dirs[dir] = children
+	}
+	s.files, s.dirs = files, dirs
+	return nil
+}
+
+// content returns the verified content of entry, from the cache when possible.
+func (f *FS) content(entry DirEntry) ([]byte, error) {
+	s := f.state
+	if data, ok := s.cache.get(entry.CID); ok {
+		return data, nil
+	}
+	rc, _, err := s.client.Open(f.ctx, entry.CID, s.secret)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	// The manifest size is not trusted for allocation; one extra byte is
+	// enough for checkDirEntry to report oversized content.
+	data, err := io.ReadAll(io.LimitReader(rc, entry.Size+1))
+	if errors.Is(err, ErrIntegrity) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("r1fs: copy cid %s: %w", entry.CID, err)
+	}
+	sum := sha256.Sum256(data)
+	if err := checkDirEntry(entry, int64(len(data)), sum[:]); err != nil {
+		return nil, err
+	}
+	s.cache.add(entry.CID, data)
+	return data, nil
+}
+
+// fsFileInfo implements fs.FileInfo for manifest entries and the directories
+// implied by their paths.
+type fsFileInfo struct {
+	name string
+	size int64
+	mode fs.FileMode
+}
+
+func fileInfoOf(entry DirEntry) *fsFileInfo {
+	mode := entry.Mode.Perm()
+	if mode == 0 {
+		mode = 0o444
+	}
+	return &fsFileInfo{name: path.Base(entry.Path), size: entry.Size, mode: mode}
+}
+
+func dirInfo(name string) *fsFileInfo {
+	return &fsFileInfo{name: path.Base(name), mode: fs.ModeDir | 0o555}
+}
+
+func (i *fsFileInfo) Name() string       { return i.name }
+func (i *fsFileInfo) Size() int64        { return i.size }
+func (i *fsFileInfo) Mode() fs.FileMode  { return i.mode }
+func (i *fsFileInfo) ModTime() time.Time { return time.Time{} }
+func (i *fsFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *fsFileInfo) Sys() any           { return nil }
+
+// fsFile is an open regular file. Its content is fetched on the first Read
+// or ReadAt; Seek only moves the offset.
+type fsFile struct {
+	fsys   *FS
+	entry  DirEntry
+	info   *fsFileInfo
+	offset int64
+
+	mu     sync.Mutex
+	data   []byte
+	loaded bool
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *fsFile) Close() error               { return nil }
+
+// content downloads the file once. A failed download is retried on the next
+// call.
+func (f *fsFile) content() ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.loaded {
+		return f.data, nil
+	}
+	data, err := f.fsys.content(f.entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: f.entry.Path, Err: err}
+	}
+	f.data, f.loaded = data, true
+	return data, nil
+}
+
+func (f *fsFile) Read(p []byte) (int, error) {
+	data, err := f.content()
+	if err != nil {
+		return 0, err
+	}
+	if f.offset >= int64(len(data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, data[f.offset:])
+	f.offset += int64(n)
+	return n, nil
+}
+
+func (f *fsFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, &fs.PathError{Op: "read", Path: f.entry.Path, Err: errNegativeOffset}
+	}
+	data, err := f.content()
+	if err != nil {
+		return 0, err
+	}
+	return bytes.NewReader(data).ReadAt(p, off)
+}
+
+func (f *fsFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.size
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.entry.Path, Err: fs.ErrInvalid}
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.entry.Path, Err: errNegativeOffset}
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// fsDir is an open directory.
+type fsDir struct {
+	info    *fsFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *fsDir) Close() error               { return nil }
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errIsDir}
+}
+
+func (d *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return append([]fs.DirEntry(nil), rest...), nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return append([]fs.DirEntry(nil), rest[:n]...), nil
+}
+
+// blockCache is a size-bounded LRU of file content keyed by CID. Content
+// larger than the whole budget is not cached.
+type blockCache struct {
+	mu    sync.Mutex
+	limit int64
+	used  int64
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+}
+
+type cacheItem struct {
+	key  string
+	data []byte
+}
+
+func newBlockCache(limit int64) *blockCache {
+	return &blockCache{limit: limit, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *blockCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheItem).data, true
+}
+
+func (c *blockCache) add(key string, data []byte) {
+	size := int64(len(data))
+	if size > c.limit {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[key]; ok {
+		return
+	}
+	c.items[key] = c.order.PushFront(&cacheItem{key: key, data: data})
+	c.used += size
+	for c.used > c.limit {
+		oldest := c.order.Back()
+		item := oldest.Value.(*cacheItem)
+		c.order.Remove(oldest)
+		delete(c.items, item.key)
+		c.used -= int64(len(item.data))
+	}
+}
+
+var (
+	_ fs.ReadFileFS  = (*FS)(nil)
+	_ fs.StatFS      = (*FS)(nil)
+	_ fs.ReadDirFS   = (*FS)(nil)
+	_ fs.ReadDirFile = (*fsDir)(nil)
+	_ io.ReaderAt    = (*fsFile)(nil)
+	_ io.Seeker      = (*fsFile)(nil)
+)
@@ -0,0 +1,147 @@
+package r1fs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/memfs"
+)
+
+// countingBackend counts content downloads.
+type countingBackend struct {
+	*memfs.Store
+	downloads int
+}
+
+func (b *countingBackend) GetFile(ctx context.Context, cid string, secret string) (*r1fs.FileLocation, error) {
+	b.downloads++
+	return b.Store.GetFile(ctx, cid, secret)
+}
+
+func (b *countingBackend) GetFileBase64(ctx context.Context, cid string, secret string) ([]byte, string, error) {
+	b.downloads++
+	return b.Store.GetFileBase64(ctx, cid, secret)
+}
+
+func TestFSServesManifestTree(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	backend := &countingBackend{Store: store}
+	client := r1fs.NewWithBackend(backend)
+	ctx := context.Background()
+
+	tree := fstest.MapFS{
+		"index.html":           {Data: []byte("<h1>home</h1>"), Mode: 0o644},
+		"static/app.js":        {Data: []byte("console.log(1)"), Mode: 0o644},
+		"static/css/site.css":  {Data: []byte("body{}"), Mode: 0o644},
+		"templates/page.tmpl":  {Data: []byte("{{.}}"), Mode: 0o600},
+		"templates/empty.tmpl": {Data: nil, Mode: 0o600},
+	}
+	manifestCID, err := client.AddDir(ctx, tree, nil)
+	if err != nil {
+		t.Fatalf("AddDir: %v", err)
+	}
+
+	fsys := r1fs.NewFS(client, manifestCID, "")
+	if err := fstest.TestFS(fsys, "index.html", "static/app.js", "static/css/site.css", "templates/page.tmpl", "templates/empty.tmpl"); err != nil {
+		t.Fatal(err)
+	}
+
+	before := backend.downloads
+	for i := 0; i < 3; i++ {
+		data, err := fs.ReadFile(fsys, "static/css/site.css")
+		if err != nil || string(data) != "body{}" {
+			t.Fatalf("ReadFile: %q err=%v", data, err)
+		}
+	}
+	if backend.downloads != before {
+		t.Fatalf("expected cached reads, saw %d downloads", backend.downloads-before)
+	}
+	info, err := fs.Stat(fsys, "templates/page.tmpl")
+	if err != nil || info.Size() != 5 || info.Mode().Perm() != 0o600 {
+		t.Fatalf("Stat: %v err=%v", info, err)
+	}
+
+	rec := httptest.NewRecorder()
+	http.FileServer(http.FS(fsys)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/app.js", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(1)" {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestFSOpenDefersDownloadUntilRead(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	backend := &countingBackend{Store: store}
+	client := r1fs.NewWithBackend(backend)
+	ctx := context.Background()
+
+	manifestCID, err := client.AddDir(ctx, fstest.MapFS{"weights.bin": {Data: []byte("0123456789")}}, nil)
+	if err != nil {
+		t.Fatalf("AddDir: %v", err)
+	}
+	fsys := r1fs.NewFS(client, manifestCID, "")
+	f, err := fsys.Open("weights.bin")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+	before := backend.downloads
+	if info, err := f.Stat(); err != nil || info.Size() != 10 {
+		t.Fatalf("Stat: %v err=%v", info, err)
+	}
+	seeker := f.(io.ReadSeeker)
+	if end, err := seeker.Seek(0, io.SeekEnd); err != nil || end != 10 {
+		t.Fatalf("Seek end: %d err=%v", end, err)
+	}
+	if _, err := seeker.Seek(4, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	if backend.downloads != before {
+		t.Fatalf("Open, Stat and Seek downloaded %d times", backend.downloads-before)
+	}
+	rest, err := io.ReadAll(seeker)
+	if err != nil || string(rest) != "456789" {
+		t.Fatalf("ReadAll: %q err=%v", rest, err)
+	}
+	if backend.downloads != before+1 {
+		t.Fatalf("expected one download on read, saw %d", backend.downloads-before)
+	}
+}
+
+func TestFSRejectsOversizedManifestEntry(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+	ctx := context.Background()
+
+	cid, err := client.AddFile(ctx, strings.NewReader("payload"), &r1fs.DataOptions{Filename: "payload.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	manifestCID, err := client.AddJSON(ctx, r1fs.DirManifest{
+		Version: r1fs.ManifestVersion,
+		Files: []r1fs.DirEntry{
+			{Path: "huge.bin", CID: cid, Size: 1 << 62},
+			{Path: "max.bin", CID: cid, Size: math.MaxInt64},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("AddJSON: %v", err)
+	}
+	fsys := r1fs.NewFS(client, manifestCID, "")
+	for _, name := range []string{"huge.bin", "max.bin"} {
+		if _, err := fs.ReadFile(fsys, name); !errors.Is(err, r1fs.ErrIntegrity) {
+			t.Fatalf("%s: expected ErrIntegrity, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package r1fs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultChunkSize     = 16 << 20
+	defaultLargeFilename = "file.bin"
+	// MaxChunkSize bounds LargeFileOptions.ChunkSize and the chunk size
+	// OpenLarge accepts from a manifest, since each chunk is held in memory.
+	MaxChunkSize = 1 << 30
+)
+
+// UploadProgress persists the state of an AddLargeFile call between runs.
+type UploadProgress interface {
+	// Load returns the recorded state, or nil when nothing was recorded.
+	Load(ctx context.Context) (*UploadState, error)
+	// Save records state after every confirmed chunk.
+	Save(ctx context.Context, state *UploadState) error
+}
+
+// FileProgress is an UploadProgress stored as JSON in a local file. The file
+// is replaced atomically on every save and can be removed once the upload
+// has completed.
+type FileProgress struct {
+	Path string
+}
+
+// NewFileProgress returns a FileProgress stored at path.
+func NewFileProgress(path string) *FileProgress {
+	return &FileProgress{Path: path}
+}
+
+// Load reads the recorded state. A missing file yields a nil state.
+func (p *FileProgress) Load(ctx context.Context) (*UploadState, error) {
+	data, err := os.ReadFile(p.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("r1fs: read upload progress: %w", err)
+	}
+	var state UploadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("r1fs: decode upload progress: %w", err)
+	}
+	return &state, nil
+}
+
+// Save writes state to a temporary file and renames it over Path.
+func (p *FileProgress) Save(ctx context.Context, state *UploadState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("r1fs: encode upload progress: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p.Path), filepath.Base(p.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("r1fs: write upload progress: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("r1fs: write upload progress: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("r1fs: write upload progress: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p.Path); err != nil {
+		return fmt.Errorf("r1fs: write upload progress: %w", err)
+	}
+	return nil
+}
+
+// AddLargeFile splits r into fixed-size chunks, uploads each one with AddFile,
+// stores a LargeFileManifest with AddJSON and returns the manifest CID.
+//
+// With opts.Progress set, every confirmed chunk is recorded. A later call
+// with the same input reads the recorded chunks again and skips their upload
+// while their size and SHA-256 still match; the first chunk that differs and
+// everything after it are uploaded anew.
+func (c *Client) AddLargeFile(ctx context.Context, r io.Reader, opts *LargeFileOptions) (cid string, err error) {
+	if r == nil {
+		return "", fmt.Errorf("r1fs: data is required")
+	}
+	if c == nil || c.backend == nil {
+		return "", fmt.Errorf("r1fs: client is nil")
+	}
+	var o LargeFileOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = defaultChunkSize
+	}
+	if o.ChunkSize > MaxChunkSize {
+		return "", fmt.Errorf("r1fs: chunk size %d exceeds %d bytes", o.ChunkSize, MaxChunkSize)
+	}
+	if strings.TrimSpace(o.Filename) == "" {
+		o.Filename = defaultLargeFilename
+	}
+
+	var recorded []LargeFileChunk
+	if o.Progress != nil {
+		state, err := o.Progress.Load(ctx)
+		if err != nil {
+			return "", err
+		}
+		if state != nil && state.ChunkSize == o.ChunkSize {
+			recorded = state.Chunks
+		}
+	}
+
+	whole := sha256.New()
+	buf := make([]byte, o.ChunkSize)
+	var (
+		chunks []LargeFileChunk
+		size   int64
+	)
+	for i := 0; ; i++ {
+		n, readErr := io.ReadFull(r, buf)
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil && readErr != io.ErrUnexpectedEOF {
+			return "", fmt.Errorf("r1fs: read chunk %d: %w", i, readErr)
+		}
+		data := buf[:n]
+		whole.Write(data)
+		sum := sha256.Sum256(data)
+		chunk := LargeFileChunk{Size: int64(n), SHA256: hex.EncodeToString(sum[:])}
+
+		if i < len(recorded) && recorded[i].CID != "" && recorded[i].Size == chunk.Size && recorded[i].SHA256 == chunk.SHA256 {
+			chunk.CID = recorded[i].CID
+			chunks = append(chunks, chunk)
+		} else {
+			// The input diverged from the recorded upload; later records are stale.
+			recorded = nil
+			chunkOpts := &DataOptions{Filename: fmt.Sprintf("%s.part%05d", o.Filename, i), Secret: o.Secret}
+			chunk.CID, err = c.AddFile(ctx, bytes.NewReader(data), chunkOpts)
+			if err != nil {
+				return "", fmt.Errorf("r1fs: upload chunk %d: %w", i, err)
+			}
+			chunks = append(chunks, chunk)
+			if o.Progress != nil {
+				if err := o.Progress.Save(ctx, &UploadState{ChunkSize: o.ChunkSize, Chunks: chunks}); err != nil {
+					return "", err
+				}
+			}
+		}
+		size += int64(n)
+		if readErr == io.ErrUnexpectedEOF {
+			break
+		}
+	}
+
+	manifest := &LargeFileManifest{
+		Version:   ManifestVersion,
+		Filename:  o.Filename,
+		Size:      size,
+		ChunkSize: o.ChunkSize,
+		SHA256:    hex.EncodeToString(whole.Sum(nil)),
+		Chunks:    chunks,
+	}
+	if manifest.Chunks == nil {
+		manifest.Chunks = []LargeFileChunk{}
+	}
+	return c.AddJSON(ctx, manifest, &DataOptions{Filename: o.Filename + ".manifest.json", Secret: o.Secret, Nonce: o.Nonce})
+}
+
+// OpenLarge returns a seekable reader over a file stored with AddLargeFile.
+// Chunks are downloaded on demand with ctx and checked against the manifest;
+// mismatches return an error wrapping ErrIntegrity. The most recently read
+// chunk is kept in memory, so manifests listing chunks larger than their
+// ChunkSize or MaxChunkSize are rejected.
+func (c *Client) OpenLarge(ctx context.Context, manifestCID string, secret string) (*LargeFile, error) {
+	doc, err := GetJSONAs[LargeFileManifest](ctx, c, manifestCID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("r1fs: manifest %s is empty: %w", manifestCID, ErrNotFound)
+	}
+	manifest := doc.Data
+	if manifest.Version != ManifestVersion {
+		return nil, fmt.Errorf("r1fs: manifest %s has unsupported version %d", manifestCID, manifest.Version)
+	}
+	if manifest.ChunkSize <= 0 || manifest.ChunkSize > MaxChunkSize {
+		return nil, fmt.Errorf("r1fs: manifest %s has invalid chunk size %d", manifestCID, manifest.ChunkSize)
+	}
+	offsets := make([]int64, len(manifest.Chunks))
+	var total int64
+	for i, chunk := range manifest.Chunks {
+		if strings.TrimSpace(chunk.CID) == "" || chunk.Size <= 0 || chunk.Size > manifest.ChunkSize {
+			return nil, fmt.Errorf("r1fs: manifest %s has an invalid chunk %d", manifestCID, i)
+		}
+		offsets[i] = total
+		total += chunk.Size
+	}
+	if total != manifest.Size {
+		return nil, fmt.Errorf("r1fs: manifest %s lists %d bytes of chunks for a %d byte file", manifestCID, total, manifest.Size)
+	}
+	return &LargeFile{
+		ctx:      ctx,
+		client:   c,
+		secret:   secret,
+		manifest: manifest,
+		offsets:  offsets,
+		cached:   -1,
+	}, nil
+}
+
+// LargeFile reads a file stored with AddLargeFile. It implements io.Reader,
+// io.ReaderAt, io.Seeker and io.Closer and is safe for concurrent use.
+type LargeFile struct {
+	ctx      context.Context
+	client   *Client
+	secret   string
+	manifest LargeFileManifest
+	offsets  []int64 // start offset of each chunk
+
+	mu     sync.Mutex
+	pos    int64
+	cached int
+	data   []byte
+	closed bool
+}
+
+// Manifest returns the manifest the file was opened from.
+func (f *LargeFile) Manifest() LargeFileManifest {
+	return f.manifest
+}
+
+// Size returns the file size in bytes.
+func (f *LargeFile) Size() int64 {
+	return f.manifest.Size
+}
+
+// Read reads from the current offset.
+func (f *LargeFile) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n, err := f.readAt(p, f.pos)
+	f.pos += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// ReadAt reads len(p) bytes starting at off, fetching chunks as needed.
+func (f *LargeFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("r1fs: negative offset %d", off)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.readAt(p, off)
+}
+
+// Seek sets the offset of the next Read.
+func (f *LargeFile) Seek(offset int64, whence int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.pos
+	case io.SeekEnd:
+		offset += f.manifest.Size
+	default:
+		return 0, fmt.Errorf("r1fs: invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("r1fs: negative offset %d", offset)
+	}
+	f.pos = offset
+	return offset, nil
+}
+
+// Close releases the cached chunk. Reads after Close fail.
+func (f *LargeFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	f.cached, f.data = -1, nil
+	return nil
+}
+
+func (f *LargeFile) readAt(p []byte, off int64) (int, error) {
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	n := 0
+	for n < len(p) {
+		if off >= f.manifest.Size {
+			return n, io.EOF
+		}
+		idx := sort.Search(len(f.offsets), func(i int) bool { return f.offsets[i] > off }) - 1
+		data, err := f.chunk(idx)
+		if err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], data[off-f.offsets[idx]:])
+		n += copied
+		off += int64(copied)
+	}
+	return n, nil
+}
+
+func (f *LargeFile) chunk(idx int) ([]byte, error) {
+	if idx == f.cached {
+		return f.data, nil
+	}
+	chunk := f.manifest.Chunks[idx]
+	rc, _, err := f.client.Open(f.ctx, chunk.CID, f.secret)
+	if err != nil {
+		return nil, fmt.Errorf("r1fs: fetch chunk %d: %w", idx, err)
+	}
+	// One extra byte is enough to detect an oversized chunk.
+	data, err := io.ReadAll(io.LimitReader(rc, chunk.Size+1))
+	rc.Close()
+	if errors.Is(err, ErrIntegrity) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("r1fs: fetch chunk %d: %w", idx, err)
+	}
+	if int64(len(data)) != chunk.Size {
+		return nil, fmt.Errorf("r1fs: chunk %d does not match the %d bytes listed in the manifest: %w", idx, chunk.Size, ErrIntegrity)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); chunk.SHA256 != "" && !strings.EqualFold(got, chunk.SHA256) {
+		return nil, fmt.Errorf("r1fs: chunk %d hashes to sha256 %s, manifest lists %s: %w", idx, got, chunk.SHA256, ErrIntegrity)
+	}
+	f.cached, f.data = idx, data
+	return f.data, nil
+}
@@ -0,0 +1,157 @@
+package r1fs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/memfs"
+)
+
+// flakyBackend fails AddFile once the upload budget is spent.
+type flakyBackend struct {
+	*memfs.Store
+	budget  int
+	uploads int
+}
+
+func (b *flakyBackend) AddFile(ctx context.Context, data []byte, opts *r1fs.DataOptions) (string, error) {
+	if b.budget == 0 {
+		return "", errors.New("connection reset")
+	}
+	b.budget--
+	b.uploads++
+	return b.Store.AddFile(ctx, data, opts)
+}
+
+func TestAddLargeFileResumesAndOpenLargeSeeks(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	backend := &flakyBackend{Store: store, budget: 3}
+	client := r1fs.NewWithBackend(backend)
+	ctx := context.Background()
+
+	payload := make([]byte, 10*1024+17)
+	rand.New(rand.NewSource(1)).Read(payload)
+	opts := &r1fs.LargeFileOptions{
+		Filename:  "weights.bin",
+		ChunkSize: 1024,
+		Progress:  r1fs.NewFileProgress(filepath.Join(t.TempDir(), "progress.json")),
+	}
+
+	if _, err := client.AddLargeFile(ctx, bytes.NewReader(payload), opts); err == nil {
+		t.Fatalf("expected the interrupted upload to fail")
+	}
+	state, err := opts.Progress.Load(ctx)
+	if err != nil || state == nil || len(state.Chunks) != 3 {
+		t.Fatalf("expected 3 recorded chunks, got %#v err=%v", state, err)
+	}
+
+	backend.budget, backend.uploads = -1, 0
+	manifestCID, err := client.AddLargeFile(ctx, bytes.NewReader(payload), opts)
+	if err != nil {
+		t.Fatalf("AddLargeFile: %v", err)
+	}
+	if backend.uploads != 8 {
+		t.Fatalf("expected the resumed upload to send the 8 remaining chunks, sent %d", backend.uploads)
+	}
+
+	f, err := client.OpenLarge(ctx, manifestCID, "")
+	if err != nil {
+		t.Fatalf("OpenLarge: %v", err)
+	}
+	defer f.Close()
+	if f.Size() != int64(len(payload)) || len(f.Manifest().Chunks) != 11 {
+		t.Fatalf("unexpected manifest: %#v", f.Manifest())
+	}
+	all, err := io.ReadAll(f)
+	if err != nil || !bytes.Equal(all, payload) {
+		t.Fatalf("ReadAll mismatch (err=%v)", err)
+	}
+	if _, err := f.Seek(-2000, io.SeekEnd); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	tail := make([]byte, 1500)
+	if _, err := io.ReadFull(f, tail); err != nil || !bytes.Equal(tail, payload[len(payload)-2000:len(payload)-500]) {
+		t.Fatalf("read after seek mismatch (err=%v)", err)
+	}
+	span := make([]byte, 100)
+	if n, err := f.ReadAt(span, 1000); n != 100 || err != nil || !bytes.Equal(span, payload[1000:1100]) {
+		t.Fatalf("ReadAt across chunks: n=%d err=%v", n, err)
+	}
+}
+
+func TestOpenLargeDetectsTamperedChunk(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+	ctx := context.Background()
+
+	other, err := client.AddFile(ctx, bytes.NewReader([]byte("0123456789")), &r1fs.DataOptions{Filename: "other"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	manifestCID, err := client.AddJSON(ctx, r1fs.LargeFileManifest{
+		Version:   r1fs.ManifestVersion,
+		Size:      10,
+		ChunkSize: 10,
+		Chunks:    []r1fs.LargeFileChunk{{CID: other, Size: 10, SHA256: "00"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("AddJSON: %v", err)
+	}
+	f, err := client.OpenLarge(ctx, manifestCID, "")
+	if err != nil {
+		t.Fatalf("OpenLarge: %v", err)
+	}
+	if _, err := io.ReadAll(f); !errors.Is(err, r1fs.ErrIntegrity) {
+		t.Fatalf("expected ErrIntegrity, got %v", err)
+	}
+}
+
+func TestOpenLargeRejectsOversizedChunks(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+	ctx := context.Background()
+
+	other, err := client.AddFile(ctx, bytes.NewReader([]byte("0123456789")), &r1fs.DataOptions{Filename: "other"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	huge := int64(1) << 50
+	manifests := []r1fs.LargeFileManifest{
+		{Version: r1fs.ManifestVersion, Size: huge, ChunkSize: huge, Chunks: []r1fs.LargeFileChunk{{CID: other, Size: huge}}},
+		{Version: r1fs.ManifestVersion, Size: huge, ChunkSize: 16, Chunks: []r1fs.LargeFileChunk{{CID: other, Size: huge}}},
+	}
+	for i, manifest := range manifests {
+		manifestCID, err := client.AddJSON(ctx, manifest, nil)
+		if err != nil {
+			t.Fatalf("AddJSON: %v", err)
+		}
+		if _, err := client.OpenLarge(ctx, manifestCID, ""); err == nil {
+			t.Fatalf("manifest %d: expected OpenLarge to reject the chunk size", i)
+		}
+	}
+
+	// A chunk longer than listed is cut off after one extra byte.
+	manifestCID, err := client.AddJSON(ctx, r1fs.LargeFileManifest{
+		Version: r1fs.ManifestVersion, Size: 4, ChunkSize: 4,
+		Chunks: []r1fs.LargeFileChunk{{CID: other, Size: 4}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("AddJSON: %v", err)
+	}
+	f, err := client.OpenLarge(ctx, manifestCID, "")
+	if err != nil {
+		t.Fatalf("OpenLarge: %v", err)
+	}
+	if _, err := io.ReadAll(f); !errors.Is(err, r1fs.ErrIntegrity) {
+		t.Fatalf("expected ErrIntegrity, got %v", err)
+	}
+}
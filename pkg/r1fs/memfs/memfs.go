@@ -0,0 +1,438 @@
+// Package memfs provides an in-memory r1fs.Backend for unit tests and offline
+// development. Blobs are content addressed with the CIDv0 identifiers IPFS
+// would assign (see package cid), remember the filename and secret supplied at
+// upload time, and can be materialised into a temporary directory through
+// GetFile.
+package memfs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/cid"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/pickle"
+)
+
+type blob struct {
+	data     []byte
+	filename string
+	secret   string
+}
+
+// Store is a concurrency-safe in-memory implementation of r1fs.Backend.
+type Store struct {
+	mu     sync.RWMutex
+	blobs  map[string]*blob
+	tmpDir string
+}
+
+var _ r1fs.Backend = (*Store)(nil)
+
+// New returns an empty Store.
+func New() *Store {
+	return &Store{blobs: make(map[string]*blob)}
+}
+
+// NewClient returns an r1fs.Client backed by a fresh Store.
+func NewClient() *r1fs.Client {
+	return r1fs.NewWithBackend(New())
+}
+
+// Close removes any files materialised by GetFile.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	dir := s.tmpDir
+	s.tmpDir = ""
+	s.mu.Unlock()
+	if dir == "" {
+		return nil
+	}
+	return os.RemoveAll(dir)
+}
+
+// AddFileBase64 stores data and returns its CID.
+func (s *Store) AddFileBase64(ctx context.Context, data []byte, opts *r1fs.DataOptions) (cid string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	name := uploadName(opts)
+	if name == "" {
+		return "", fmt.Errorf("memfs: filename or filepath is required")
+	}
+	return s.put(data, name, opts), nil
+}
+
+// AddFile stores data and returns its CID.
+func (s *Store) AddFile(ctx context.Context, data []byte, opts *r1fs.DataOptions) (cid string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	name := uploadName(opts)
+	if name == "" {
+		return "", fmt.Errorf("memfs: filename or filepath is required")
+	}
+	return s.put(data, name, opts), nil
+}
+
+// GetFileBase64 returns the stored bytes and filename for cid.
+func (s *Store) GetFileBase64(ctx context.Context, cid string, secret string) (fileData []byte, fileName string, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	b, err := s.lookup(cid, secret)
+	if err != nil {
+		return nil, "", err
+	}
+	return append([]byte(nil), b.data...), b.filename, nil
+}
+
+// GetFile writes the blob for cid into a temporary directory and reports its path.
+func (s *Store) GetFile(ctx context.Context, cid string, secret string) (location *r1fs.FileLocation, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	b, err := s.lookup(cid, secret)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := s.materializeDir()
+	if err != nil {
+		return nil, err
+	}
+	target := filepath.Join(dir, cid, filepath.Base(b.filename))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return nil, fmt.Errorf("memfs: create file directory: %w", err)
+	}
+	if err := os.WriteFile(target, b.data, 0o644); err != nil {
+		return nil, fmt.Errorf("memfs: materialise file: %w", err)
+	}
+	return &r1fs.FileLocation{
+		Path:     target,
+		Filename: b.filename,
+		Meta: map[string]any{
+			"file":     target,
+			"filename": b.filename,
+		},
+	}, nil
+}
+
+// DeleteFile removes cid from the store. With CleanupLocalFiles set, the copy
+// materialised by GetFile is removed as well; the other options have nothing
+// to act on in memory.
+func (s *Store) DeleteFile(ctx context.Context, cid string, opts *r1fs.DeleteOptions) (*r1fs.DeleteFileResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !s.remove(cid, opts) {
+		return &r1fs.DeleteFileResult{Success: false, Message: "file not found", CID: cid}, nil
+	}
+	return &r1fs.DeleteFileResult{Success: true, Message: "file deleted", CID: cid}, nil
+}
+
+// DeleteFiles removes every CID in cids, reporting which ones were present.
+// Options apply as in DeleteFile.
+func (s *Store) DeleteFiles(ctx context.Context, cids []string, opts *r1fs.DeleteOptions) (*r1fs.DeleteFilesResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result := &r1fs.DeleteFilesResult{
+		Success: []string{},
+		Failed:  []string{},
+		Total:   len(cids),
+	}
+	for _, cid := range cids {
+		if s.remove(cid, opts) {
+			result.Success = append(result.Success, cid)
+		} else {
+			result.Failed = append(result.Failed, cid)
+		}
+	}
+	result.SuccessCount = len(result.Success)
+	result.FailedCount = len(result.Failed)
+	return result, nil
+}
+
+// AddJSON stores data as a JSON document and returns its CID.
+func (s *Store) AddJSON(ctx context.Context, data any, opts *r1fs.DataOptions) (cid string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	payload, err := encodeJSON(data)
+	if err != nil {
+		return "", fmt.Errorf("memfs: encode JSON: %w", err)
+	}
+	return s.put(payload, documentName(opts, ".json"), opts), nil
+}
+
+// AddPickle stores data pickled the way the node does and returns its CID.
+func (s *Store) AddPickle(ctx context.Context, data any, opts *r1fs.DataOptions) (cid string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	payload, err := encodePickle(data)
+	if err != nil {
+		return "", err
+	}
+	return s.put(payload, documentName(opts, ".pkl"), opts), nil
+}
+
+// CalculateJSONCID returns the CID AddJSON would assign to data with nonce.
+func (s *Store) CalculateJSONCID(ctx context.Context, data any, nonce int, opts *r1fs.DataOptions) (cid string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	payload, err := encodeJSON(data)
+	if err != nil {
+		return "", fmt.Errorf("memfs: encode JSON: %w", err)
+	}
+	return contentID(payload, secretOf(opts), &nonce), nil
+}
+
+// CalculatePickleCID returns the CID AddPickle would assign to data with nonce.
+func (s *Store) CalculatePickleCID(ctx context.Context, data any, nonce int, opts *r1fs.DataOptions) (cid string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	payload, err := encodePickle(data)
+	if err != nil {
+		return "", err
+	}
+	return contentID(payload, secretOf(opts), &nonce), nil
+}
+
+// AddYAML stores data as a YAML document and returns its CID. Documents are
+// serialised as JSON, which is a valid YAML representation.
+func (s *Store) AddYAML(ctx context.Context, data any, opts *r1fs.DataOptions) (cid string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	payload, err := encodeJSON(data)
+	if err != nil {
+		return "", fmt.Errorf("memfs: encode YAML: %w", err)
+	}
+	return s.put(payload, documentName(opts, ".yaml"), opts), nil
+}
+
+// GetYAML returns the document stored under cid wrapped in the upstream
+// {"file_data": ...} shape, or the "error" string when it cannot be read. A
+// missing cid is reported with an error wrapping r1fs.ErrNotFound.
+func (s *Store) GetYAML(ctx context.Context, cid string, secret string) (payload []byte, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	b, err := s.lookup(cid, secret)
+	if errors.Is(err, r1fs.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil || !json.Valid(b.data) {
+		return []byte(`"error"`), nil
+	}
+	return json.Marshal(struct {
+		FileData json.RawMessage `json:"file_data"`
+	}{FileData: b.data})
+}
+
+type snapshotBlob struct {
+	Filename string `json:"filename"`
+	Secret   string `json:"secret,omitempty"`
+}
+
+// Snapshot writes the filename and secret of every stored blob to w as JSON.
+// Content is left out so that callers can persist it once per CID (see CIDs
+// and Content) and hand it back to Restore.
+func (s *Store) Snapshot(w io.Writer) error {
+	s.mu.RLock()
+	snap := make(map[string]snapshotBlob, len(s.blobs))
+	for cid, b := range s.blobs {
+		snap[cid] = snapshotBlob{Filename: b.filename, Secret: b.secret}
+	}
+	s.mu.RUnlock()
+	if err := json.NewEncoder(w).Encode(snap); err != nil {
+		return fmt.Errorf("memfs: write snapshot: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the stored blobs with a snapshot previously written by
+// Snapshot, reading the content of each CID through content.
+func (s *Store) Restore(r io.Reader, content func(cid string) ([]byte, error)) error {
+	var snap map[string]snapshotBlob
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return fmt.Errorf("memfs: read snapshot: %w", err)
+	}
+	blobs := make(map[string]*blob, len(snap))
+	for cid, b := range snap {
+		data, err := content(cid)
+		if err != nil {
+			return fmt.Errorf("memfs: read content of %s: %w", cid, err)
+		}
+		blobs[cid] = &blob{data: data, filename: b.Filename, secret: b.Secret}
+	}
+	s.mu.Lock()
+	s.blobs = blobs
+	s.mu.Unlock()
+	return nil
+}
+
+// CIDs returns the stored CIDs in sorted order.
+func (s *Store) CIDs() []string {
+	s.mu.RLock()
+	cids := make([]string, 0, len(s.blobs))
+	for cid := range s.blobs {
+		cids = append(cids, cid)
+	}
+	s.mu.RUnlock()
+	sort.Strings(cids)
+	return cids
+}
+
+// Content returns the bytes stored under cid, whatever secret they were
+// uploaded with.
+func (s *Store) Content(cid string) ([]byte, bool) {
+	s.mu.RLock()
+	b, ok := s.blobs[cid]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), b.data...), true
+}
+
+func (s *Store) put(data []byte, filename string, opts *r1fs.DataOptions) string {
+	var nonce *int
+	if opts != nil {
+		nonce = opts.Nonce
+	}
+	secret := secretOf(opts)
+	cid := contentID(data, secret, nonce)
+	s.mu.Lock()
+	s.blobs[cid] = &blob{
+		data:     append([]byte(nil), data...),
+		filename: filename,
+		secret:   secret,
+	}
+	s.mu.Unlock()
+	return cid
+}
+
+func (s *Store) lookup(cid, secret string) (*blob, error) {
+	s.mu.RLock()
+	b, ok := s.blobs[strings.TrimSpace(cid)]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("memfs: cid %s: %w", cid, r1fs.ErrNotFound)
+	}
+	if b.secret != "" && b.secret != secret {
+		return nil, fmt.Errorf("memfs: invalid secret for cid %s", cid)
+	}
+	return b, nil
+}
+
+func (s *Store) remove(cid string, opts *r1fs.DeleteOptions) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.blobs[cid]; !ok {
+		return false
+	}
+	delete(s.blobs, cid)
+	if opts != nil && opts.CleanupLocalFiles != nil && *opts.CleanupLocalFiles && s.tmpDir != "" {
+		_ = os.RemoveAll(filepath.Join(s.tmpDir, cid))
+	}
+	return true
+}
+
+func (s *Store) materializeDir() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tmpDir != "" {
+		return s.tmpDir, nil
+	}
+	dir, err := os.MkdirTemp("", "r1fs-memfs-")
+	if err != nil {
+		return "", fmt.Errorf("memfs: create temp dir: %w", err)
+	}
+	s.tmpDir = dir
+	return dir, nil
+}
+
+// contentID returns the CIDv0 `ipfs add` assigns to data, which is what
+// cid.Sum, cid.SumJSON and cid.SumPickle predict. For uploads with a secret
+// the secret and nonce are mixed into the digest instead, so encrypted
+// uploads of identical content receive distinct CIDs, as they do on a live
+// node. Without a secret the nonce does not affect the CID.
+func contentID(data []byte, secret string, nonce *int) string {
+	if secret == "" {
+		if c, err := cid.SumBytes(data, nil); err == nil {
+			return c.String()
+		}
+	}
+	h := sha256.New()
+	h.Write(data)
+	h.Write([]byte{0})
+	h.Write([]byte(secret))
+	if nonce != nil {
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.Itoa(*nonce)))
+	}
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return cid.NewV0(sum).String()
+}
+
+func uploadName(opts *r1fs.DataOptions) string {
+	if opts == nil {
+		return ""
+	}
+	if name := strings.TrimSpace(opts.Filename); name != "" {
+		return name
+	}
+	if path := strings.TrimSpace(opts.FilePath); path != "" {
+		return filepath.Base(path)
+	}
+	return ""
+}
+
+func documentName(opts *r1fs.DataOptions, ext string) string {
+	if name := uploadName(opts); name != "" {
+		return name
+	}
+	return "data" + ext
+}
+
+func secretOf(opts *r1fs.DataOptions) string {
+	if opts == nil {
+		return ""
+	}
+	return strings.TrimSpace(opts.Secret)
+}
+
+// encodePickle pickles the JSON encoding of data, as the node does for
+// /add_pickle.
+func encodePickle(data any) ([]byte, error) {
+	doc, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("memfs: encode pickle payload: %w", err)
+	}
+	payload, err := pickle.MarshalJSON(doc)
+	if err != nil {
+		return nil, fmt.Errorf("memfs: %w", err)
+	}
+	return payload, nil
+}
+
+// encodeJSON serialises documents like the node does, so that their CIDs match
+// cid.SumJSON.
+func encodeJSON(payload any) ([]byte, error) {
+	return cid.MarshalJSON(payload)
+}
@@ -0,0 +1,155 @@
+package memfs_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/cid"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/memfs"
+)
+
+func TestStoreFileRoundTrip(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+	ctx := context.Background()
+
+	cid, err := client.AddFile(ctx, strings.NewReader("hello world"), &r1fs.DataOptions{FilePath: "/tmp/hello.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	// `ipfs add` of "hello world".
+	if cid != "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD" {
+		t.Fatalf("expected the IPFS CIDv0, got %q", cid)
+	}
+	again, err := client.AddFileBase64(ctx, strings.NewReader("hello world"), &r1fs.DataOptions{Filename: "other.txt"})
+	if err != nil {
+		t.Fatalf("AddFileBase64: %v", err)
+	}
+	if again != cid {
+		t.Fatalf("expected identical content to share a CID: %s != %s", again, cid)
+	}
+
+	data, name, err := client.GetFileBase64(ctx, cid, "")
+	if err != nil {
+		t.Fatalf("GetFileBase64: %v", err)
+	}
+	if string(data) != "hello world" || name != "other.txt" {
+		t.Fatalf("unexpected download: %q name=%q", data, name)
+	}
+
+	loc, err := client.GetFile(ctx, cid, "")
+	if err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+	onDisk, err := os.ReadFile(loc.Path)
+	if err != nil {
+		t.Fatalf("read materialised file: %v", err)
+	}
+	if string(onDisk) != "hello world" || loc.Filename != "other.txt" {
+		t.Fatalf("unexpected location: %#v content=%q", loc, onDisk)
+	}
+
+	if _, _, err := client.GetFileBase64(ctx, "QmMissing", ""); !errors.Is(err, r1fs.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStoreSecrets(t *testing.T) {
+	client := memfs.NewClient()
+	ctx := context.Background()
+
+	plain, err := client.AddFile(ctx, strings.NewReader("payload"), &r1fs.DataOptions{Filename: "a.bin"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	secret, err := client.AddFile(ctx, strings.NewReader("payload"), &r1fs.DataOptions{Filename: "a.bin", Secret: "s3"})
+	if err != nil {
+		t.Fatalf("AddFile secret: %v", err)
+	}
+	if plain == secret {
+		t.Fatalf("expected secret upload to receive a distinct CID")
+	}
+	if _, _, err := client.GetFileBase64(ctx, secret, "wrong"); err == nil {
+		t.Fatalf("expected error for wrong secret")
+	}
+	if _, _, err := client.GetFileBase64(ctx, secret, "s3"); err != nil {
+		t.Fatalf("GetFileBase64 with secret: %v", err)
+	}
+}
+
+func TestStoreDocumentsAndDeletes(t *testing.T) {
+	client := memfs.NewClient()
+	ctx := context.Background()
+
+	nonce := 7
+	jsonCID, err := client.AddJSON(ctx, map[string]any{"name": "ratio1"}, &r1fs.DataOptions{Nonce: &nonce})
+	if err != nil {
+		t.Fatalf("AddJSON: %v", err)
+	}
+	calc, err := client.CalculateJSONCID(ctx, map[string]any{"name": "ratio1"}, nonce, nil)
+	if err != nil {
+		t.Fatalf("CalculateJSONCID: %v", err)
+	}
+	if calc != jsonCID {
+		t.Fatalf("calculated CID %s does not match stored CID %s", calc, jsonCID)
+	}
+
+	pickleCID, err := client.AddPickle(ctx, map[string]any{"scores": []any{1, 2.5}}, nil)
+	if err != nil {
+		t.Fatalf("AddPickle: %v", err)
+	}
+	want, err := cid.SumPickle(map[string]any{"scores": []any{1, 2.5}}, nil)
+	if err != nil {
+		t.Fatalf("SumPickle: %v", err)
+	}
+	if pickleCID != want.String() {
+		t.Fatalf("pickle CID %s, want %s", pickleCID, want)
+	}
+	calcPickle, err := client.CalculatePickleCID(ctx, map[string]any{"scores": []any{1, 2.5}}, nonce, nil)
+	if err != nil || calcPickle != want.String() {
+		t.Fatalf("CalculatePickleCID: %s err=%v, want %s", calcPickle, err, want)
+	}
+	if jsonWant, err := cid.SumJSON(map[string]any{"name": "ratio1"}, nil); err != nil || jsonCID != jsonWant.String() {
+		t.Fatalf("JSON CID %s, want %v (err=%v)", jsonCID, jsonWant, err)
+	}
+	pickled, err := client.GetPickle(ctx, pickleCID, "", nil)
+	if err != nil {
+		t.Fatalf("GetPickle: %v", err)
+	}
+	scores := pickled.Data.(map[string]any)["scores"].([]any)
+	if scores[0] != int64(1) || scores[1] != 2.5 {
+		t.Fatalf("unexpected pickle document: %#v", pickled.Data)
+	}
+
+	yamlCID, err := client.AddYAML(ctx, map[string]any{"count": 2}, &r1fs.DataOptions{Filename: "config.yaml"})
+	if err != nil {
+		t.Fatalf("AddYAML: %v", err)
+	}
+	var doc map[string]any
+	if _, err := client.GetYAML(ctx, yamlCID, "", &doc); err != nil {
+		t.Fatalf("GetYAML: %v", err)
+	}
+	if doc["count"] != float64(2) {
+		t.Fatalf("unexpected YAML document: %#v", doc)
+	}
+	if _, err := client.GetYAML(ctx, "QmMissing", "", nil); err == nil {
+		t.Fatalf("expected error for missing YAML document")
+	}
+
+	res, err := client.DeleteFile(ctx, yamlCID, nil)
+	if err != nil || !res.Success || res.CID != yamlCID {
+		t.Fatalf("DeleteFile: %#v err=%v", res, err)
+	}
+	bulk, err := client.DeleteFiles(ctx, []string{jsonCID, yamlCID}, nil)
+	if err != nil {
+		t.Fatalf("DeleteFiles: %v", err)
+	}
+	if bulk.Total != 2 || bulk.SuccessCount != 1 || bulk.FailedCount != 1 || bulk.Failed[0] != yamlCID {
+		t.Fatalf("unexpected bulk delete result: %#v", bulk)
+	}
+}
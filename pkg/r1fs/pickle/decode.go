@@ -0,0 +1,886 @@
+package pickle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal decodes a pickle (protocols 0 to 5) into plain Go values:
+// None → nil, bool, int → int64 or *big.Int, float → float64, str → string,
+// bytes/bytearray → []byte, list/tuple/set → []any, dict → map[string]any and
+// numpy arrays → *NDArray. Dict keys are converted to strings the way
+// json.dumps does. Other class instances are rejected. Objects referenced
+// more than once decode to a single shared Go value.
+func Unmarshal(data []byte) (any, error) {
+	u := &unpickler{data: data, memo: make(map[int]any)}
+	value, err := u.run()
+	if err != nil {
+		return nil, fmt.Errorf("pickle: decode: %w", err)
+	}
+	return newConverter().toGo(value)
+}
+
+// Intermediate mutable containers; memoized references must observe later
+// APPEND and SETITEM opcodes.
+type (
+	pyList   struct{ items []any }
+	pyDict   struct{ keys, values []any }
+	pySet    struct{ items []any }
+	pyTuple  []any
+	pyGlobal struct{ module, name string }
+	pyMark   struct{}
+)
+
+type unpickler struct {
+	data  []byte
+	pos   int
+	stack []any
+	marks []int
+	memo  map[int]any
+}
+
+var errTruncated = errors.New("unexpected end of data")
+
+func (u *unpickler) read(n int) ([]byte, error) {
+	if n < 0 || u.pos+n > len(u.data) {
+		return nil, errTruncated
+	}
+	b := u.data[u.pos : u.pos+n]
+	u.pos += n
+	return b, nil
+}
+
+func (u *unpickler) readLine() (string, error) {
+	idx := bytes.IndexByte(u.data[u.pos:], '\n')
+	if idx < 0 {
+		return "", errTruncated
+	}
+	line := string(u.data[u.pos : u.pos+idx])
+	u.pos += idx + 1
+	return line, nil
+}
+
+func (u *unpickler) readUint(n int) (uint64, error) {
+	b, err := u.read(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for i := n - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+	return v, nil
+}
+
+func (u *unpickler) push(v any) { u.stack = append(u.stack, v) }
+
+func (u *unpickler) pop() (any, error) {
+	items, err := u.popN(1)
+	if err != nil {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+// fence is the stack height below which opcodes may not reach: the position
+// of the innermost MARK, or zero.
+func (u *unpickler) fence() int {
+	if len(u.marks) == 0 {
+		return 0
+	}
+	return u.marks[len(u.marks)-1]
+}
+
+// popN removes and returns the top n items without crossing the innermost
+// MARK.
+func (u *unpickler) popN(n int) ([]any, error) {
+	if len(u.stack)-n < u.fence() {
+		return nil, errors.New("stack underflow")
+	}
+	items := append([]any(nil), u.stack[len(u.stack)-n:]...)
+	u.stack = u.stack[:len(u.stack)-n]
+	return items, nil
+}
+
+func (u *unpickler) top() (any, error) {
+	if len(u.stack) <= u.fence() {
+		return nil, errors.New("stack underflow")
+	}
+	return u.stack[len(u.stack)-1], nil
+}
+
+// popMark returns the items pushed since the last MARK.
+func (u *unpickler) popMark() ([]any, error) {
+	if len(u.marks) == 0 {
+		return nil, errors.New("missing mark")
+	}
+	m := u.marks[len(u.marks)-1]
+	if m > len(u.stack) {
+		return nil, errors.New("stack underflow")
+	}
+	u.marks = u.marks[:len(u.marks)-1]
+	items := append([]any(nil), u.stack[m:]...)
+	u.stack = u.stack[:m]
+	return items, nil
+}
+
+func (u *unpickler) run() (any, error) {
+	for {
+		op, err := u.read(1)
+		if err != nil {
+			return nil, err
+		}
+		switch op[0] {
+		case 0x80: // PROTO
+			if _, err := u.read(1); err != nil {
+				return nil, err
+			}
+		case 0x95: // FRAME
+			if _, err := u.read(8); err != nil {
+				return nil, err
+			}
+		case '.': // STOP
+			return u.pop()
+		case '(': // MARK
+			u.marks = append(u.marks, len(u.stack))
+		case '0': // POP
+			if _, err := u.pop(); err != nil {
+				return nil, err
+			}
+		case '1': // POP_MARK
+			if _, err := u.popMark(); err != nil {
+				return nil, err
+			}
+		case '2': // DUP
+			v, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			u.push(v)
+		case 'N':
+			u.push(nil)
+		case 0x88:
+			u.push(true)
+		case 0x89:
+			u.push(false)
+		case 'I': // INT
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			switch line {
+			case "01":
+				u.push(true)
+			case "00":
+				u.push(false)
+			default:
+				v, err := parseInt(line)
+				if err != nil {
+					return nil, err
+				}
+				u.push(v)
+			}
+		case 'L': // LONG
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			v, err := parseInt(strings.TrimSuffix(line, "L"))
+			if err != nil {
+				return nil, err
+			}
+			u.push(v)
+		case 'J': // BININT
+			v, err := u.readUint(4)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int64(int32(uint32(v))))
+		case 'K': // BININT1
+			v, err := u.readUint(1)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int64(v))
+		case 'M': // BININT2
+			v, err := u.readUint(2)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int64(v))
+		case 0x8a, 0x8b: // LONG1, LONG4
+			width := 1
+			if op[0] == 0x8b {
+				width = 4
+			}
+			n, err := u.readUint(width)
+			if err != nil {
+				return nil, err
+			}
+			b, err := u.read(int(n))
+			if err != nil {
+				return nil, err
+			}
+			u.push(decodeLong(b))
+		case 'F': // FLOAT
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			v, err := strconv.ParseFloat(line, 64)
+			if err != nil {
+				return nil, err
+			}
+			u.push(v)
+		case 'G': // BINFLOAT
+			b, err := u.read(8)
+			if err != nil {
+				return nil, err
+			}
+			u.push(math.Float64frombits(binary.BigEndian.Uint64(b)))
+		case 'S': // STRING
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			v, err := strconv.Unquote(pyQuoted(line))
+			if err != nil {
+				return nil, fmt.Errorf("invalid STRING argument %q", line)
+			}
+			u.push(v)
+		case 'V': // UNICODE (raw-unicode-escape)
+			line, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			u.push(rawUnicodeUnescape(line))
+		case 'T', 'U', 'X', 0x8c, 0x8d, 'B', 'C', 0x8e, 0x96:
+			if err := u.loadSized(op[0]); err != nil {
+				return nil, err
+			}
+		case ']':
+			u.push(&pyList{})
+		case 'l': // LIST
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			u.push(&pyList{items: items})
+		case 'a': // APPEND
+			v, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			if err := u.extend([]any{v}); err != nil {
+				return nil, err
+			}
+		case 'e': // APPENDS
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			if err := u.extend(items); err != nil {
+				return nil, err
+			}
+		case ')':
+			u.push(pyTuple{})
+		case 't': // TUPLE
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			u.push(pyTuple(items))
+		case 0x85, 0x86, 0x87: // TUPLE1-3
+			items, err := u.popN(int(op[0]-0x85) + 1)
+			if err != nil {
+				return nil, err
+			}
+			u.push(pyTuple(items))
+		case '}':
+			u.push(&pyDict{})
+		case 'd': // DICT
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			d := &pyDict{}
+			if err := d.setItems(items); err != nil {
+				return nil, err
+			}
+			u.push(d)
+		case 's': // SETITEM
+			v, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			k, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			if err := u.setItems([]any{k, v}); err != nil {
+				return nil, err
+			}
+		case 'u': // SETITEMS
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			if err := u.setItems(items); err != nil {
+				return nil, err
+			}
+		case 0x8f: // EMPTY_SET
+			u.push(&pySet{})
+		case 0x90: // ADDITEMS
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			top, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			set, ok := top.(*pySet)
+			if !ok {
+				return nil, errors.New("ADDITEMS target is not a set")
+			}
+			set.items = append(set.items, items...)
+		case 0x91: // FROZENSET
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			u.push(&pySet{items: items})
+		case 'p', 'q', 'r', 0x94: // PUT, BINPUT, LONG_BINPUT, MEMOIZE
+			idx, err := u.memoIndex(op[0], len(u.memo))
+			if err != nil {
+				return nil, err
+			}
+			v, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			u.memo[idx] = v
+		case 'g', 'h', 'j': // GET, BINGET, LONG_BINGET
+			idx, err := u.memoIndex(op[0], 0)
+			if err != nil {
+				return nil, err
+			}
+			v, ok := u.memo[idx]
+			if !ok {
+				return nil, fmt.Errorf("memo key %d not found", idx)
+			}
+			u.push(v)
+		case 'c': // GLOBAL
+			module, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			name, err := u.readLine()
+			if err != nil {
+				return nil, err
+			}
+			u.push(pyGlobal{module: module, name: name})
+		case 0x93: // STACK_GLOBAL
+			name, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			module, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			m, ok1 := module.(string)
+			n, ok2 := name.(string)
+			if !ok1 || !ok2 {
+				return nil, errors.New("STACK_GLOBAL requires string operands")
+			}
+			u.push(pyGlobal{module: m, name: n})
+		case 'R', 0x81: // REDUCE, NEWOBJ
+			args, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			fn, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			tuple, ok := args.(pyTuple)
+			if !ok {
+				return nil, errors.New("call arguments are not a tuple")
+			}
+			v, err := u.call(fn, tuple)
+			if err != nil {
+				return nil, err
+			}
+			u.push(v)
+		case 'b': // BUILD
+			state, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			if err := u.build(state); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported opcode 0x%02x at offset %d", op[0], u.pos-1)
+		}
+	}
+}
+
+// loadSized handles the length-prefixed string and bytes opcodes.
+func (u *unpickler) loadSized(op byte) error {
+	width := map[byte]int{'T': 4, 'U': 1, 'X': 4, 0x8c: 1, 0x8d: 8, 'B': 4, 'C': 1, 0x8e: 8, 0x96: 8}[op]
+	n, err := u.readUint(width)
+	if err != nil {
+		return err
+	}
+	if n > uint64(len(u.data)) {
+		return errTruncated
+	}
+	b, err := u.read(int(n))
+	if err != nil {
+		return err
+	}
+	switch op {
+	case 'T', 'U', 'X', 0x8c, 0x8d:
+		u.push(string(b))
+	default:
+		u.push(append([]byte(nil), b...))
+	}
+	return nil
+}
+
+func (u *unpickler) memoIndex(op byte, next int) (int, error) {
+	switch op {
+	case 0x94:
+		return next, nil
+	case 'p', 'g':
+		line, err := u.readLine()
+		if err != nil {
+			return 0, err
+		}
+		return strconv.Atoi(line)
+	case 'q', 'h':
+		v, err := u.readUint(1)
+		return int(v), err
+	default:
+		v, err := u.readUint(4)
+		return int(v), err
+	}
+}
+
+func (u *unpickler) extend(items []any) error {
+	top, err := u.top()
+	if err != nil {
+		return err
+	}
+	list, ok := top.(*pyList)
+	if !ok {
+		return errors.New("APPEND target is not a list")
+	}
+	list.items = append(list.items, items...)
+	return nil
+}
+
+func (u *unpickler) setItems(items []any) error {
+	top, err := u.top()
+	if err != nil {
+		return err
+	}
+	d, ok := top.(*pyDict)
+	if !ok {
+		return errors.New("SETITEM target is not a dict")
+	}
+	return d.setItems(items)
+}
+
+func (d *pyDict) setItems(items []any) error {
+	if len(items)%2 != 0 {
+		return errors.New("odd number of dict items")
+	}
+	for i := 0; i < len(items); i += 2 {
+		d.keys = append(d.keys, items[i])
+		d.values = append(d.values, items[i+1])
+	}
+	return nil
+}
+
+// call evaluates the builtin constructors the standard pickler emits.
+func (u *unpickler) call(fn any, args pyTuple) (any, error) {
+	g, ok := fn.(pyGlobal)
+	if !ok {
+		return nil, errors.New("call target is not a global")
+	}
+	switch g.module + "." + g.name {
+	case "_codecs.encode":
+		// Protocol 2 pickles bytes as _codecs.encode(str, "latin1").
+		if len(args) < 1 {
+			return nil, errors.New("_codecs.encode: missing argument")
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, errors.New("_codecs.encode: argument is not a str")
+		}
+		out := make([]byte, 0, len(s))
+		for _, r := range s {
+			if r > 0xff {
+				return nil, errors.New("_codecs.encode: non latin-1 character")
+			}
+			out = append(out, byte(r))
+		}
+		return out, nil
+	case "__builtin__.set", "builtins.set", "__builtin__.frozenset", "builtins.frozenset":
+		set := &pySet{}
+		if len(args) > 0 {
+			list, ok := args[0].(*pyList)
+			if !ok {
+				return nil, fmt.Errorf("%s: argument is not a list", g.name)
+			}
+			set.items = append(set.items, list.items...)
+		}
+		return set, nil
+	case "__builtin__.bytearray", "builtins.bytearray", "__builtin__.bytes", "builtins.bytes":
+		if len(args) == 0 {
+			return []byte{}, nil
+		}
+		switch v := args[0].(type) {
+		case []byte:
+			return v, nil
+		case string:
+			return []byte(v), nil
+		}
+		return nil, fmt.Errorf("%s: unsupported argument", g.name)
+	case "collections.OrderedDict", "builtins.dict", "__builtin__.dict":
+		return &pyDict{}, nil
+	case "numpy.core.multiarray._reconstruct", "numpy._core.multiarray._reconstruct":
+		return &NDArray{}, nil
+	case "numpy.dtype":
+		if len(args) < 1 {
+			return nil, errors.New("numpy.dtype: missing argument")
+		}
+		descr, ok := args[0].(string)
+		if !ok {
+			return nil, errors.New("numpy.dtype: descriptor is not a str")
+		}
+		return &pyDType{descr: descr, order: "|"}, nil
+	case "numpy.core.numeric._frombuffer", "numpy._core.numeric._frombuffer":
+		return frombuffer(args)
+	}
+	return nil, fmt.Errorf("unsupported global %s.%s", g.module, g.name)
+}
+
+func (u *unpickler) build(state any) error {
+	top, err := u.top()
+	if err != nil {
+		return err
+	}
+	switch t := top.(type) {
+	case *pyDict:
+		if state == nil {
+			return nil
+		}
+	case *pyDType:
+		// (version, byteorder, subdescr, names, fields, elsize, alignment, flags)
+		st, ok := state.(pyTuple)
+		if !ok || len(st) < 2 {
+			return errors.New("numpy.dtype: invalid state")
+		}
+		order, ok := st[1].(string)
+		if !ok {
+			return errors.New("numpy.dtype: invalid byte order")
+		}
+		t.order = order
+		return nil
+	case *NDArray:
+		// (version, shape, dtype, is_fortran, data)
+		st, ok := state.(pyTuple)
+		if !ok || len(st) != 5 {
+			return errors.New("numpy.ndarray: invalid state")
+		}
+		return fillArray(t, st[1], st[2], st[3], st[4])
+	}
+	return fmt.Errorf("BUILD on %T is not supported", top)
+}
+
+// pyDType is a numpy.dtype under construction.
+type pyDType struct {
+	descr string
+	order string
+}
+
+func (d *pyDType) String() string {
+	if d.order == "" || d.order == "=" {
+		return "<" + d.descr
+	}
+	return d.order + d.descr
+}
+
+func fillArray(a *NDArray, shape, dtype, fortran, data any) error {
+	dims, ok := shape.(pyTuple)
+	if !ok {
+		return errors.New("numpy.ndarray: shape is not a tuple")
+	}
+	a.Shape = make([]int, len(dims))
+	for i, d := range dims {
+		n, ok := d.(int64)
+		if !ok || n < 0 {
+			return errors.New("numpy.ndarray: invalid dimension")
+		}
+		a.Shape[i] = int(n)
+	}
+	dt, ok := dtype.(*pyDType)
+	if !ok {
+		return errors.New("numpy.ndarray: invalid dtype")
+	}
+	a.DType = dt.String()
+	a.Fortran, _ = fortran.(bool)
+	switch raw := data.(type) {
+	case []byte:
+		a.Data = raw
+	case string:
+		a.Data = []byte(raw)
+	default:
+		return errors.New("numpy.ndarray: only arrays with raw buffers are supported")
+	}
+	return nil
+}
+
+// frombuffer handles the protocol 5 form _frombuffer(buffer, dtype, shape, order).
+func frombuffer(args pyTuple) (*NDArray, error) {
+	if len(args) != 4 {
+		return nil, errors.New("numpy._frombuffer: unexpected arguments")
+	}
+	a := &NDArray{}
+	order, _ := args[3].(string)
+	if err := fillArray(a, args[2], args[1], order == "F", args[0]); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func parseInt(s string) (any, error) {
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return v, nil
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return v, nil
+}
+
+// decodeLong decodes a little-endian two's complement integer.
+func decodeLong(b []byte) any {
+	if len(b) == 0 {
+		return int64(0)
+	}
+	if len(b) <= 8 {
+		var v int64
+		for i := len(b) - 1; i >= 0; i-- {
+			v = v<<8 | int64(b[i])
+		}
+		shift := uint(64 - 8*len(b))
+		return v << shift >> shift
+	}
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	v := new(big.Int).SetBytes(be)
+	if b[len(b)-1]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	if v.IsInt64() {
+		return v.Int64()
+	}
+	return v
+}
+
+// pyQuoted converts a Python repr string literal into a Go quoted string.
+func pyQuoted(s string) string {
+	if len(s) < 2 || s[0] != s[len(s)-1] || (s[0] != '\'' && s[0] != '"') {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	inner := s[1 : len(s)-1]
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		switch {
+		case c == '\\' && i+1 < len(inner) && inner[i+1] == '\'':
+			b.WriteByte('\'')
+			i++
+		case c == '\\' && i+1 < len(inner):
+			b.WriteByte(c)
+			b.WriteByte(inner[i+1])
+			i++
+		case c == '"':
+			b.WriteString(`\"`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func rawUnicodeUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+5 < len(s) && s[i+1] == 'u' {
+			if v, err := strconv.ParseUint(s[i+2:i+6], 16, 32); err == nil {
+				b.WriteRune(rune(v))
+				i += 5
+				continue
+			}
+		}
+		if s[i] == '\\' && i+9 < len(s) && s[i+1] == 'U' {
+			if v, err := strconv.ParseUint(s[i+2:i+10], 16, 32); err == nil {
+				b.WriteRune(rune(v))
+				i += 9
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// maxElements bounds the number of list, tuple, set and dict elements
+// Unmarshal produces, so that small hostile pickles cannot expand into huge
+// values.
+const maxElements = 1 << 24
+
+// converter turns decoder containers into plain Go values. Containers
+// referenced more than once through the memo are converted once and shared.
+type converter struct {
+	active map[any]bool // containers on the current path, to detect cycles
+	done   map[any]any  // converted containers by identity
+	elems  int
+}
+
+func newConverter() *converter {
+	return &converter{active: make(map[any]bool), done: make(map[any]any)}
+}
+
+// identity returns the key under which a container is cached, or nil for
+// values that are not shared by reference.
+func identity(v any) any {
+	switch t := v.(type) {
+	case *pyList, *pyDict, *pySet:
+		return t
+	case pyTuple:
+		if len(t) > 0 {
+			return &t[0]
+		}
+	}
+	return nil
+}
+
+// toGo converts decoder containers into plain Go values.
+func (c *converter) toGo(v any) (any, error) {
+	switch t := v.(type) {
+	case *pyList, *pySet, pyTuple, *pyDict:
+		id := identity(t)
+		if id == nil {
+			return []any{}, nil
+		}
+		if out, ok := c.done[id]; ok {
+			return out, nil
+		}
+		if c.active[id] {
+			return nil, errors.New("pickle: decode: recursive structure")
+		}
+		c.active[id] = true
+		out, err := c.convert(t)
+		delete(c.active, id)
+		if err != nil {
+			return nil, err
+		}
+		c.done[id] = out
+		return out, nil
+	case pyGlobal:
+		return nil, fmt.Errorf("pickle: decode: unsupported value %s.%s", t.module, t.name)
+	case *pyDType:
+		return nil, fmt.Errorf("pickle: decode: unsupported value numpy.dtype(%s)", t)
+	default:
+		return v, nil
+	}
+}
+
+func (c *converter) convert(v any) (any, error) {
+	switch t := v.(type) {
+	case *pyList:
+		return c.convertItems(t.items)
+	case *pySet:
+		return c.convertItems(t.items)
+	case pyTuple:
+		return c.convertItems(t)
+	case *pyDict:
+		if err := c.grow(len(t.keys)); err != nil {
+			return nil, err
+		}
+		out := make(map[string]any, len(t.keys))
+		for i, k := range t.keys {
+			key, err := dictKey(k)
+			if err != nil {
+				return nil, err
+			}
+			val, err := c.toGo(t.values[i])
+			if err != nil {
+				return nil, err
+			}
+			out[key] = val
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+func (c *converter) convertItems(items []any) ([]any, error) {
+	if err := c.grow(len(items)); err != nil {
+		return nil, err
+	}
+	out := make([]any, len(items))
+	for i, item := range items {
+		v, err := c.toGo(item)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func (c *converter) grow(n int) error {
+	c.elems += n
+	if c.elems > maxElements {
+		return fmt.Errorf("pickle: decode: more than %d elements", maxElements)
+	}
+	return nil
+}
+
+// dictKey renders a dict key the way json.dumps does.
+func dictKey(k any) (string, error) {
+	switch t := k.(type) {
+	case string:
+		return t, nil
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	case int64:
+		return strconv.FormatInt(t, 10), nil
+	case *big.Int:
+		return t.String(), nil
+	case float64:
+		return strconv.FormatFloat(t, 'g', -1, 64), nil
+	}
+	return "", fmt.Errorf("pickle: decode: unsupported dict key of type %T", k)
+}
@@ -0,0 +1,10 @@
+// Package pickle encodes and decodes the subset of Python's pickle format used
+// by Ratio1 plugins: dicts, lists, tuples, sets, str, bytes, int, float, bool,
+// None and numpy arrays backed by raw buffers.
+//
+// Marshal produces the exact bytes CPython's pickle.dumps emits for the
+// equivalent Python value (protocol 4 by default), including framing and memo
+// references, so the CID of a pickle can be computed without a node.
+// MarshalJSON reproduces pickle.dumps(json.loads(doc)), which is how the R1FS
+// node stores objects sent to /add_pickle.
+package pickle
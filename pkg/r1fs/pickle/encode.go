@@ -0,0 +1,736 @@
+package pickle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultProtocol is the protocol CPython 3.8 to 3.13 use by default.
+const DefaultProtocol = 4
+
+const (
+	frameSizeMin    = 4
+	frameSizeTarget = 64 * 1024
+	batchSize       = 1000
+)
+
+// Marshal encodes v as a protocol 4 pickle. Supported values are nil, bool,
+// integers, *big.Int, floats, string, []byte, slices and arrays (lists), maps
+// with string or integer keys (dicts, sorted by key), Tuple, Dict and NDArray.
+// Other values, such as structs, are converted through their JSON encoding.
+func Marshal(v any) ([]byte, error) {
+	return MarshalProtocol(v, DefaultProtocol)
+}
+
+// MarshalProtocol encodes v with pickle protocol 4 or 5.
+func MarshalProtocol(v any, protocol int) ([]byte, error) {
+	if protocol != 4 && protocol != 5 {
+		return nil, fmt.Errorf("pickle: unsupported protocol %d", protocol)
+	}
+	e := &encoder{memo: make(map[memoKey]int)}
+	e.out.Write([]byte{opProto, byte(protocol)})
+	e.framing = true
+	if err := e.save(v); err != nil {
+		return nil, err
+	}
+	e.write(opStop)
+	e.commitFrame(true)
+	return e.out.Bytes(), nil
+}
+
+// MarshalJSON encodes the JSON document data the way Python's
+// pickle.dumps(json.loads(data)) does.
+func MarshalJSON(data []byte) ([]byte, error) {
+	v, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return Marshal(v)
+}
+
+const (
+	opProto          = 0x80
+	opFrame          = 0x95
+	opStop           = '.'
+	opMark           = '('
+	opNone           = 'N'
+	opTrue           = 0x88
+	opFalse          = 0x89
+	opBinInt         = 'J'
+	opBinInt1        = 'K'
+	opBinInt2        = 'M'
+	opLong1          = 0x8a
+	opLong4          = 0x8b
+	opBinFloat       = 'G'
+	opShortBinUnicde = 0x8c
+	opBinUnicode     = 'X'
+	opBinUnicode8    = 0x8d
+	opShortBinBytes  = 'C'
+	opBinBytes       = 'B'
+	opBinBytes8      = 0x8e
+	opEmptyList      = ']'
+	opAppend         = 'a'
+	opAppends        = 'e'
+	opEmptyTuple     = ')'
+	opTuple          = 't'
+	opTuple1         = 0x85
+	opEmptyDict      = '}'
+	opSetItem        = 's'
+	opSetItems       = 'u'
+	opMemoize        = 0x94
+	opBinGet         = 'h'
+	opLongBinGet     = 'j'
+	opStackGlobal    = 0x93
+	opReduce         = 'R'
+	opBuild          = 'b'
+)
+
+// memoKey identifies values that CPython would pickle as the same object:
+// empty and single latin-1 character strings and bytes are interned, and
+// json.loads shares dict key strings across a document.
+type memoKey struct {
+	kind byte // 's' str, 'k' dict key, 'b' bytes
+	val  string
+}
+
+type encoder struct {
+	out     bytes.Buffer
+	frame   bytes.Buffer
+	framing bool
+	memo    map[memoKey]int
+	nextID  int
+}
+
+func (e *encoder) write(p ...byte) {
+	if e.framing {
+		e.frame.Write(p)
+		return
+	}
+	e.out.Write(p)
+}
+
+// commitFrame flushes the current frame once it reaches the target size, or
+// unconditionally when force is set, mirroring pickle._Framer.
+func (e *encoder) commitFrame(force bool) {
+	if !e.framing || (e.frame.Len() < frameSizeTarget && !force) {
+		return
+	}
+	if e.frame.Len() >= frameSizeMin {
+		var hdr [9]byte
+		hdr[0] = opFrame
+		binary.LittleEndian.PutUint64(hdr[1:], uint64(e.frame.Len()))
+		e.out.Write(hdr[:])
+	}
+	e.out.Write(e.frame.Bytes())
+	e.frame.Reset()
+}
+
+// writeLarge emits a large str or bytes payload outside of any frame.
+func (e *encoder) writeLarge(header []byte, payload []byte) {
+	e.commitFrame(true)
+	e.out.Write(header)
+	e.out.Write(payload)
+}
+
+func (e *encoder) memoize(key *memoKey) {
+	if key != nil {
+		e.memo[*key] = e.nextID
+	}
+	e.nextID++
+	e.write(opMemoize)
+}
+
+// memoGet writes a memo reference when key was pickled before.
+func (e *encoder) memoGet(key *memoKey) bool {
+	if key == nil {
+		return false
+	}
+	idx, ok := e.memo[*key]
+	if !ok {
+		return false
+	}
+	if idx < 256 {
+		e.write(opBinGet, byte(idx))
+	} else {
+		var b [5]byte
+		b[0] = opLongBinGet
+		binary.LittleEndian.PutUint32(b[1:], uint32(idx))
+		e.write(b[:]...)
+	}
+	return true
+}
+
+func (e *encoder) save(v any) error {
+	e.commitFrame(false)
+	switch t := v.(type) {
+	case nil:
+		e.write(opNone)
+	case bool:
+		if t {
+			e.write(opTrue)
+		} else {
+			e.write(opFalse)
+		}
+	case int:
+		e.saveInt(big.NewInt(int64(t)))
+	case int64:
+		e.saveInt(big.NewInt(t))
+	case int32:
+		e.saveInt(big.NewInt(int64(t)))
+	case uint64:
+		e.saveInt(new(big.Int).SetUint64(t))
+	case *big.Int:
+		if t == nil {
+			e.write(opNone)
+			return nil
+		}
+		e.saveInt(t)
+	case float64:
+		e.saveFloat(t)
+	case float32:
+		e.saveFloat(float64(t))
+	case json.Number:
+		return e.saveNumber(t)
+	case string:
+		e.saveStr(t, false)
+	case []byte:
+		e.saveBytes(t)
+	case Tuple:
+		return e.saveTuple(t)
+	case []any:
+		return e.saveList(t)
+	case Dict:
+		return e.saveDict(t)
+	case map[string]any:
+		return e.saveDict(sortedItems(t))
+	case NDArray:
+		return e.saveNDArray(&t)
+	case *NDArray:
+		if t == nil {
+			e.write(opNone)
+			return nil
+		}
+		return e.saveNDArray(t)
+	default:
+		return e.saveReflect(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+func (e *encoder) saveInt(n *big.Int) {
+	if n.IsInt64() {
+		x := n.Int64()
+		switch {
+		case x >= 0 && x <= 0xff:
+			e.write(opBinInt1, byte(x))
+			return
+		case x >= 0 && x <= 0xffff:
+			e.write(opBinInt2, byte(x), byte(x>>8))
+			return
+		case x >= math.MinInt32 && x <= math.MaxInt32:
+			var b [5]byte
+			b[0] = opBinInt
+			binary.LittleEndian.PutUint32(b[1:], uint32(int32(x)))
+			e.write(b[:]...)
+			return
+		}
+	}
+	encoded := encodeLong(n)
+	if len(encoded) < 256 {
+		e.write(append([]byte{opLong1, byte(len(encoded))}, encoded...)...)
+		return
+	}
+	var hdr [5]byte
+	hdr[0] = opLong4
+	binary.LittleEndian.PutUint32(hdr[1:], uint32(len(encoded)))
+	e.write(append(hdr[:], encoded...)...)
+}
+
+// encodeLong returns the minimal little-endian two's complement encoding of n,
+// as pickle.encode_long does.
+func encodeLong(n *big.Int) []byte {
+	if n.Sign() == 0 {
+		return nil
+	}
+	nbytes := (n.BitLen() >> 3) + 1
+	mod := new(big.Int).Lsh(big.NewInt(1), uint(8*nbytes))
+	v := new(big.Int).Set(n)
+	if v.Sign() < 0 {
+		v.Add(v, mod)
+	}
+	be := v.FillBytes(make([]byte, nbytes))
+	out := make([]byte, nbytes)
+	for i := range be {
+		out[nbytes-1-i] = be[i]
+	}
+	if n.Sign() < 0 && nbytes > 1 && out[nbytes-1] == 0xff && out[nbytes-2]&0x80 != 0 {
+		out = out[:nbytes-1]
+	}
+	return out
+}
+
+func (e *encoder) saveFloat(f float64) {
+	var b [9]byte
+	b[0] = opBinFloat
+	binary.BigEndian.PutUint64(b[1:], math.Float64bits(f))
+	e.write(b[:]...)
+}
+
+// saveNumber encodes a JSON number as json.loads would: integers without a
+// fraction or exponent become int, everything else float.
+func (e *encoder) saveNumber(n json.Number) error {
+	s := string(n)
+	if !strings.ContainsAny(s, ".eE") {
+		v, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return fmt.Errorf("pickle: invalid number %q", s)
+		}
+		e.saveInt(v)
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("pickle: invalid number %q: %w", s, err)
+	}
+	e.saveFloat(f)
+	return nil
+}
+
+func (e *encoder) saveStr(s string, dictKey bool) {
+	e.saveStrKey(s, strKey(s, dictKey))
+}
+
+// saveInterned writes a string CPython interns, such as module and attribute
+// names, so every occurrence is the same object.
+func (e *encoder) saveInterned(s string) {
+	key := strKey(s, false)
+	if key == nil {
+		key = &memoKey{kind: 'i', val: s}
+	}
+	e.saveStrKey(s, key)
+}
+
+func (e *encoder) saveStrKey(s string, key *memoKey) {
+	if e.memoGet(key) {
+		return
+	}
+	n := len(s)
+	switch {
+	case n <= 0xff:
+		e.write(append([]byte{opShortBinUnicde, byte(n)}, s...)...)
+	case uint64(n) > math.MaxUint32:
+		var hdr [9]byte
+		hdr[0] = opBinUnicode8
+		binary.LittleEndian.PutUint64(hdr[1:], uint64(n))
+		e.writeLarge(hdr[:], []byte(s))
+	default:
+		var hdr [5]byte
+		hdr[0] = opBinUnicode
+		binary.LittleEndian.PutUint32(hdr[1:], uint32(n))
+		if n >= frameSizeTarget {
+			e.writeLarge(hdr[:], []byte(s))
+		} else {
+			e.write(append(hdr[:], s...)...)
+		}
+	}
+	e.memoize(key)
+}
+
+// strKey returns the memo identity of s, or nil when CPython would create a
+// distinct object for it.
+func strKey(s string, dictKey bool) *memoKey {
+	if r, size := utf8.DecodeRuneInString(s); s == "" || (size == len(s) && r < 0x100) {
+		return &memoKey{kind: 's', val: s}
+	}
+	if dictKey {
+		return &memoKey{kind: 'k', val: s}
+	}
+	return nil
+}
+
+func (e *encoder) saveBytes(b []byte) {
+	var key *memoKey
+	if len(b) <= 1 {
+		key = &memoKey{kind: 'b', val: string(b)}
+	}
+	if e.memoGet(key) {
+		return
+	}
+	n := len(b)
+	switch {
+	case n <= 0xff:
+		e.write(append([]byte{opShortBinBytes, byte(n)}, b...)...)
+	case uint64(n) > math.MaxUint32:
+		var hdr [9]byte
+		hdr[0] = opBinBytes8
+		binary.LittleEndian.PutUint64(hdr[1:], uint64(n))
+		e.writeLarge(hdr[:], b)
+	default:
+		var hdr [5]byte
+		hdr[0] = opBinBytes
+		binary.LittleEndian.PutUint32(hdr[1:], uint32(n))
+		if n >= frameSizeTarget {
+			e.writeLarge(hdr[:], b)
+		} else {
+			e.write(append(hdr[:], b...)...)
+		}
+	}
+	e.memoize(key)
+}
+
+func (e *encoder) saveTuple(items Tuple) error {
+	if len(items) == 0 {
+		e.write(opEmptyTuple)
+		return nil
+	}
+	if len(items) <= 3 {
+		for _, item := range items {
+			if err := e.save(item); err != nil {
+				return err
+			}
+		}
+		e.write(opTuple1 + byte(len(items)-1))
+		e.memoize(nil)
+		return nil
+	}
+	e.write(opMark)
+	for _, item := range items {
+		if err := e.save(item); err != nil {
+			return err
+		}
+	}
+	e.write(opTuple)
+	e.memoize(nil)
+	return nil
+}
+
+func (e *encoder) saveList(items []any) error {
+	e.write(opEmptyList)
+	e.memoize(nil)
+	for start := 0; ; start += batchSize {
+		batch := items[start:]
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		switch {
+		case len(batch) > 1:
+			e.write(opMark)
+			for _, item := range batch {
+				if err := e.save(item); err != nil {
+					return err
+				}
+			}
+			e.write(opAppends)
+		case len(batch) == 1:
+			if err := e.save(batch[0]); err != nil {
+				return err
+			}
+			e.write(opAppend)
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+	}
+}
+
+func (e *encoder) saveDict(items Dict) error {
+	e.write(opEmptyDict)
+	e.memoize(nil)
+	saveItem := func(item DictItem) error {
+		if s, ok := item.Key.(string); ok {
+			e.commitFrame(false)
+			e.saveStr(s, true)
+		} else if err := e.save(item.Key); err != nil {
+			return err
+		}
+		return e.save(item.Value)
+	}
+	for start := 0; ; start += batchSize {
+		batch := items[start:]
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		switch {
+		case len(batch) > 1:
+			e.write(opMark)
+			for _, item := range batch {
+				if err := saveItem(item); err != nil {
+					return err
+				}
+			}
+			e.write(opSetItems)
+		case len(batch) == 1:
+			if err := saveItem(batch[0]); err != nil {
+				return err
+			}
+			e.write(opSetItem)
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+	}
+}
+
+// saveGlobal writes a module attribute reference with STACK_GLOBAL. Globals
+// are memoized, so later references become memo lookups.
+func (e *encoder) saveGlobal(module, name string) {
+	e.commitFrame(false)
+	key := &memoKey{kind: 'g', val: module + "." + name}
+	if e.memoGet(key) {
+		return
+	}
+	e.commitFrame(false)
+	e.saveInterned(module)
+	e.commitFrame(false)
+	e.saveInterned(name)
+	e.write(opStackGlobal)
+	e.memoize(key)
+}
+
+// saveNDArray writes the form numpy's ndarray.__reduce__ produces:
+// _reconstruct(ndarray, (0,), b'b') followed by BUILD with the array state
+// (1, shape, dtype, is_fortran, data).
+func (e *encoder) saveNDArray(a *NDArray) error {
+	order, descr := "<", a.DType
+	if descr != "" && strings.ContainsRune("<>|=", rune(descr[0])) {
+		order, descr = descr[:1], descr[1:]
+	}
+	if descr == "" {
+		return errors.New("pickle: NDArray requires a dtype")
+	}
+	e.saveGlobal("numpy.core.multiarray", "_reconstruct")
+	e.saveGlobal("numpy", "ndarray")
+	if err := e.save(Tuple{0}); err != nil {
+		return err
+	}
+	e.commitFrame(false)
+	e.saveBytes([]byte("b"))
+	e.write(opTuple1 + 2)
+	e.memoize(nil)
+	e.write(opReduce)
+	e.memoize(nil)
+
+	shape := make(Tuple, len(a.Shape))
+	for i, d := range a.Shape {
+		shape[i] = d
+	}
+	e.write(opMark)
+	if err := e.save(1); err != nil {
+		return err
+	}
+	if err := e.save(shape); err != nil {
+		return err
+	}
+	if err := e.saveDType(order, descr); err != nil {
+		return err
+	}
+	if err := e.save(a.Fortran); err != nil {
+		return err
+	}
+	if err := e.save(a.Data); err != nil {
+		return err
+	}
+	e.write(opTuple)
+	e.memoize(nil)
+	e.write(opBuild)
+	return nil
+}
+
+// saveDType writes numpy.dtype(descr, 0, 1) with its state. Builtin
+// dtypes are singletons in numpy, so repeated dtypes are memo lookups.
+func (e *encoder) saveDType(order, descr string) error {
+	e.commitFrame(false)
+	key := &memoKey{kind: 'd', val: order + descr}
+	if e.memoGet(key) {
+		return nil
+	}
+	e.saveGlobal("numpy", "dtype")
+	if err := e.save(Tuple{descr, 0, 1}); err != nil {
+		return err
+	}
+	e.write(opReduce)
+	e.memoize(key)
+	if err := e.save(Tuple{3, order, nil, nil, nil, -1, -1, 0}); err != nil {
+		return err
+	}
+	e.write(opBuild)
+	return nil
+}
+
+func (e *encoder) saveReflect(rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Invalid:
+		e.write(opNone)
+		return nil
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			e.write(opNone)
+			return nil
+		}
+		return e.save(rv.Elem().Interface())
+	case reflect.Bool:
+		return e.save(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.saveInt(big.NewInt(rv.Int()))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.saveInt(new(big.Int).SetUint64(rv.Uint()))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		e.saveFloat(rv.Float())
+		return nil
+	case reflect.String:
+		e.saveStr(rv.String(), false)
+		return nil
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			e.saveBytes(b)
+			return nil
+		}
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			e.write(opNone)
+			return nil
+		}
+		items := make([]any, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return e.saveList(items)
+	case reflect.Map:
+		if rv.IsNil() {
+			e.write(opNone)
+			return nil
+		}
+		items, err := mapItems(rv)
+		if err != nil {
+			return err
+		}
+		return e.saveDict(items)
+	}
+	data, err := json.Marshal(rv.Interface())
+	if err != nil {
+		return fmt.Errorf("pickle: unsupported type %s: %w", rv.Type(), err)
+	}
+	v, err := decodeJSON(data)
+	if err != nil {
+		return err
+	}
+	return e.save(v)
+}
+
+func sortedItems(m map[string]any) Dict {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	items := make(Dict, len(keys))
+	for i, k := range keys {
+		items[i] = DictItem{Key: k, Value: m[k]}
+	}
+	return items
+}
+
+func mapItems(rv reflect.Value) (Dict, error) {
+	items := make(Dict, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		k := iter.Key()
+		switch k.Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return nil, fmt.Errorf("pickle: unsupported map key type %s", k.Type())
+		}
+		items = append(items, DictItem{Key: k.Interface(), Value: iter.Value().Interface()})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		a, b := reflect.ValueOf(items[i].Key), reflect.ValueOf(items[j].Key)
+		switch a.Kind() {
+		case reflect.String:
+			return a.String() < b.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		default:
+			return a.Uint() < b.Uint()
+		}
+	})
+	return items, nil
+}
+
+// decodeJSON parses data into values mirroring json.loads: objects keep their
+// key order (later duplicates replace the value in place) and numbers stay
+// json.Number so integers and floats are told apart.
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, fmt.Errorf("pickle: decode JSON: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("pickle: decode JSON: trailing data")
+	}
+	return v, nil
+}
+
+func decodeJSONValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			items := []any{}
+			for dec.More() {
+				v, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, v)
+			}
+			_, err := dec.Token()
+			return items, err
+		case '{':
+			items := Dict{}
+			index := map[string]int{}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := keyTok.(string)
+				v, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				if i, ok := index[key]; ok {
+					items[i].Value = v
+					continue
+				}
+				index[key] = len(items)
+				items = append(items, DictItem{Key: key, Value: v})
+			}
+			_, err := dec.Token()
+			return items, err
+		}
+		return nil, fmt.Errorf("unexpected delimiter %v", t)
+	default:
+		return t, nil
+	}
+}
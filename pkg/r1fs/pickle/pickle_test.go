@@ -0,0 +1,203 @@
+package pickle_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/pickle"
+)
+
+// Expected values were produced with CPython 3.11:
+// pickle.dumps(json.loads(doc), 4).
+func TestMarshalJSONMatchesCPython(t *testing.T) {
+	doc := `{"name": "r1fs", "count": 3, "tags": ["a", "a", "bb"], "big": 1180591620717411303424, "neg": -300, "f": 0.5, "n": null, "ok": true, "nested": {"name": "x"}}`
+	want := "80049579000000000000007d94288c046e616d65948c0472316673948c05636f756e74944b038c0474616773945d94288c01619468068c02626294658c03626967948a090000000000000000408c036e6567944ad4feffff8c016694473fe00000000000008c016e944e8c026f6b94888c066e6573746564947d9468018c01789473752e"
+	got, err := pickle.MarshalJSON([]byte(doc))
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if hex.EncodeToString(got) != want {
+		t.Fatalf("MarshalJSON = %x\nwant %s", got, want)
+	}
+
+	p5, err := pickle.MarshalProtocol(map[string]any{"name": "r1fs"}, 5)
+	if err != nil {
+		t.Fatalf("MarshalProtocol: %v", err)
+	}
+	if p5[0] != 0x80 || p5[1] != 5 {
+		t.Fatalf("unexpected protocol header %x", p5[:2])
+	}
+}
+
+func TestMarshalJSONFramesLargeDocuments(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < 3000; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, `{"id": %d, "name": "item-%d", "tags": ["a", "bb"], "score": %d.%d, "blob": "%s"}`,
+			i, i, i/2, (i%2)*5, strings.Repeat("x", i%7))
+	}
+	b.WriteString("]")
+
+	cases := map[string]string{
+		b.String(): "a45b8330d8ecf98bd4e7d6beaf1d301c5206b1f477054c0635b65959d81b6be2",
+		`{"big": "` + strings.Repeat("y", 70000) + `", "after": [1, 2, 3]}`: "41f648f793b53e349e11dba4f19e3aac55f684c5157bfb0943f63a684a37f69b",
+	}
+	for doc, want := range cases {
+		got, err := pickle.MarshalJSON([]byte(doc))
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		if sum := sha256.Sum256(got); hex.EncodeToString(sum[:]) != want {
+			t.Fatalf("pickle of %d byte document differs from CPython", len(doc))
+		}
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	big70 := new(big.Int).Lsh(big.NewInt(1), 70)
+	value := pickle.Dict{
+		{Key: "tuple", Value: pickle.Tuple{1, "two", 3.5, nil}},
+		{Key: 7, Value: []byte{0xde, 0xad}},
+		{Key: "list", Value: []string{"a", "b"}},
+		{Key: "map", Value: map[string]int{"z": 1, "a": -70000}},
+		{Key: "big", Value: big70},
+		{Key: "struct", Value: struct {
+			Name string `json:"name"`
+		}{Name: "r1"}},
+	}
+	data, err := pickle.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	got, err := pickle.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := map[string]any{
+		"tuple":  []any{int64(1), "two", 3.5, nil},
+		"7":      []byte{0xde, 0xad},
+		"list":   []any{"a", "b"},
+		"map":    map[string]any{"a": int64(-70000), "z": int64(1)},
+		"big":    big70,
+		"struct": map[string]any{"name": "r1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("round trip = %#v", got)
+	}
+}
+
+// numpyFixture pickles {'a': float64 array of shape (2, 3), 'b': [float64
+// array of shape (1,), uint8 array [1, 2, 3, 4]]} the way numpy's
+// ndarray.__reduce__ lays it out.
+const numpyFixture = "80049536010000000000007d94288c0161948c156e756d70792e636f72652e6d756c74696172726179948c0c5f7265636f6e7374727563749493948c056e756d7079948c076e6461727261799493944b0085944301629487945294284b014b024b03869468058c0564747970659493948c026638944b004b0187945294284b038c013c944e4e4e4affffffff4affffffff4b00749462894330000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f947494628c0162945d9428680468076808680987945294284b014b0185946811894308000000000000000094749462680468076808680987945294284b014b048594680e8c027531944b004b0187945294284b038c017c944e4e4e4affffffff4affffffff4b00749462894304010203049474946265752e"
+
+func TestNumpyArrays(t *testing.T) {
+	raw, err := hex.DecodeString(numpyFixture)
+	if err != nil {
+		t.Fatalf("fixture: %v", err)
+	}
+	decoded, err := pickle.Unmarshal(raw)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	seq := make([]byte, 48)
+	for i := range seq {
+		seq[i] = byte(i)
+	}
+	want := map[string]any{
+		"a": &pickle.NDArray{DType: "<f8", Shape: []int{2, 3}, Data: seq},
+		"b": []any{
+			&pickle.NDArray{DType: "<f8", Shape: []int{1}, Data: make([]byte, 8)},
+			&pickle.NDArray{DType: "|u1", Shape: []int{4}, Data: []byte{1, 2, 3, 4}},
+		},
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Fatalf("decoded %#v", decoded)
+	}
+
+	encoded, err := pickle.Marshal(pickle.Dict{{Key: "a", Value: want["a"]}, {Key: "b", Value: want["b"]}})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	again, err := pickle.Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(again, want) {
+		t.Fatalf("round trip %#v", again)
+	}
+}
+
+func TestUnmarshalRejectsUnknownClasses(t *testing.T) {
+	// pickle.dumps(datetime.date(2024, 1, 2), 4)
+	raw, _ := hex.DecodeString("80049520000000000000008c086461746574696d65948c0464617465949394430407e8010294859452942e")
+	if _, err := pickle.Unmarshal(raw); err == nil || !strings.Contains(err.Error(), "datetime.date") {
+		t.Fatalf("expected unsupported global error, got %v", err)
+	}
+}
+
+func TestUnmarshalSharedReferencesDecodeOnce(t *testing.T) {
+	// a = []
+	// for _ in range(26): a = [a, a]
+	// pickle.dumps(a, 4)
+	raw, _ := hex.DecodeString("8004959f000000000000005d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94285d94681a65681965681865681765681665681565681465681365681265681165681065680f65680e65680d65680c65680b65680a656809656808656807656806656805656804656803656802656801652e")
+	decoded, err := pickle.Unmarshal(raw)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	depth := 0
+	for v := decoded; ; depth++ {
+		items, ok := v.([]any)
+		if !ok {
+			t.Fatalf("level %d decoded to %T", depth, v)
+		}
+		if len(items) == 0 {
+			break
+		}
+		if len(items) != 2 || !reflect.DeepEqual(items[0], items[1]) {
+			t.Fatalf("level %d: unexpected items %d", depth, len(items))
+		}
+		v = items[0]
+	}
+	if depth != 26 {
+		t.Fatalf("depth = %d, want 26", depth)
+	}
+}
+
+func TestUnmarshalRejectsOpcodesCrossingMark(t *testing.T) {
+	raw, _ := hex.DecodeString(numpyFixture)
+	// Found by FuzzUnmarshal: TUPLE3 consumes items below an open MARK, and
+	// the next MARK-consuming opcode used to slice past the stack.
+	fuzzed := strings.Replace(string(raw), "\x94(", "\x942(1C\x010(\x87(", 1)
+	cases := map[string]string{
+		"fuzzed numpy":   fuzzed,
+		"tuple2 at mark": "\x80\x04K\x01K\x02(\x86t.",
+		"pop at mark":    "\x80\x04K\x01(0t.",
+		"memo at mark":   "\x80\x04K\x01(\x94t.",
+		"append at mark": "\x80\x04](K\x01at.",
+	}
+	for name, data := range cases {
+		if _, err := pickle.Unmarshal([]byte(data)); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	for _, fixture := range []string{numpyFixture, "80049520000000000000008c086461746574696d65948c0464617465949394430407e8010294859452942e"} {
+		raw, _ := hex.DecodeString(fixture)
+		f.Add(raw)
+	}
+	f.Add([]byte("\x80\x04K\x01K\x02(\x86t."))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = pickle.Unmarshal(data)
+	})
+}
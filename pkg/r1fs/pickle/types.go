@@ -0,0 +1,24 @@
+package pickle
+
+// Tuple encodes as a Python tuple.
+type Tuple []any
+
+// Dict encodes as a Python dict whose items keep their order. Use it instead of
+// a Go map when key order matters or keys are not strings.
+type Dict []DictItem
+
+// DictItem is a single key/value pair of a Dict.
+type DictItem struct {
+	Key   any
+	Value any
+}
+
+// NDArray holds a numpy array as a raw buffer.
+type NDArray struct {
+	// DType is the numpy type string including byte order, e.g. "<f8" or "|u1".
+	DType string
+	Shape []int
+	// Fortran reports column-major element order.
+	Fortran bool
+	Data    []byte
+}
@@ -0,0 +1,231 @@
+package r1fs
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Ratio1/edge_sdk_go/internal/httpx"
+	"github.com/Ratio1/edge_sdk_go/internal/ratio1api"
+)
+
+// Opener returns a fresh reader positioned at the start of an upload payload.
+type Opener func() (io.ReadCloser, error)
+
+// UploadSource describes a payload streamed to the upstream.
+type UploadSource struct {
+	// Open is called once per attempt to obtain the payload.
+	Open Opener
+	// Replayable reports whether Open may be called again for a retry.
+	Replayable bool
+}
+
+// StreamingBackend is an optional Backend extension for backends that can
+// upload a file without holding it in memory. Client.AddFile and
+// Client.AddFileFrom prefer it over Backend.AddFile when available.
+type StreamingBackend interface {
+	AddFileStream(ctx context.Context, src UploadSource, opts *DataOptions) (cid string, err error)
+}
+
+var errSourceConsumed = errors.New("r1fs: upload source cannot be replayed")
+
+// readerSource adapts r into an UploadSource. Seekable readers are rewound to
+// their current offset for every attempt; other readers can only be sent once.
+func readerSource(r io.Reader) UploadSource {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		if start, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			return UploadSource{
+				Open: func() (io.ReadCloser, error) {
+					if _, err := rs.Seek(start, io.SeekStart); err != nil {
+						return nil, fmt.Errorf("r1fs: rewind upload source: %w", err)
+					}
+					return io.NopCloser(rs), nil
+				},
+				Replayable: true,
+			}
+		}
+	}
+	var once sync.Once
+	return UploadSource{
+		Open: func() (io.ReadCloser, error) {
+			err := errSourceConsumed
+			once.Do(func() { err = nil })
+			if err != nil {
+				return nil, err
+			}
+			return io.NopCloser(r), nil
+		},
+	}
+}
+
+func bytesSource(data []byte) UploadSource {
+	return UploadSource{
+		Open: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		},
+		Replayable: true,
+	}
+}
+
+// readSource drains src into memory for backends without streaming support.
+func readSource(src UploadSource) ([]byte, error) {
+	rc, err := src.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("r1fs: read upload payload: %w", err)
+	}
+	return data, nil
+}
+
+// multipartStream produces the /add_file multipart body through an io.Pipe so
+// the payload is copied straight from the source to the connection. Every call
+// to body starts a new attempt with the same boundary.
+type multipartStream struct {
+	src      UploadSource
+	filename string
+	meta     []byte
+	boundary string
+
+	mu   sync.Mutex
+	pipe *io.PipeReader
+	done chan struct{}
+}
+
+func newMultipartStream(src UploadSource, filename string, meta []byte) (*multipartStream, error) {
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return nil, fmt.Errorf("r1fs: generate multipart boundary: %w", err)
+	}
+	return &multipartStream{
+		src:      src,
+		filename: filename,
+		meta:     meta,
+		boundary: hex.EncodeToString(raw[:]),
+	}, nil
+}
+
+func (m *multipartStream) contentType() string {
+	return "multipart/form-data; boundary=" + m.boundary
+}
+
+func (m *multipartStream) body() (io.ReadCloser, error) {
+	m.stop()
+	src, err := m.src.Open()
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer src.Close()
+		pw.CloseWithError(m.write(pw, src))
+	}()
+	m.mu.Lock()
+	m.pipe, m.done = pr, done
+	m.mu.Unlock()
+	return pr, nil
+}
+
+// stop aborts the writer of the previous attempt and waits for it to release
+// the source, so a retry never reads the source concurrently.
+func (m *multipartStream) stop() {
+	m.mu.Lock()
+	pipe, done := m.pipe, m.done
+	m.pipe, m.done = nil, nil
+	m.mu.Unlock()
+	if pipe == nil {
+		return
+	}
+	_ = pipe.CloseWithError(io.ErrClosedPipe)
+	<-done
+}
+
+func (m *multipartStream) write(w io.Writer, src io.Reader) error {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(m.boundary); err != nil {
+		return fmt.Errorf("r1fs: set multipart boundary: %w", err)
+	}
+	filePart, err := writer.CreateFormFile("file", m.filename)
+	if err != nil {
+		return fmt.Errorf("r1fs: create multipart part: %w", err)
+	}
+	if _, err := io.Copy(filePart, src); err != nil {
+		return fmt.Errorf("r1fs: write multipart payload: %w", err)
+	}
+	if err := writer.WriteField("body_json", string(m.meta)); err != nil {
+		return fmt.Errorf("r1fs: write multipart metadata: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("r1fs: finalize multipart body: %w", err)
+	}
+	return nil
+}
+
+// AddFileStream uploads src to /add_file without buffering it. Non-replayable
+// sources are sent with retries disabled.
+func (b *httpBackend) AddFileStream(ctx context.Context, src UploadSource, opts *DataOptions) (cid string, err error) {
+	if b == nil || b.client == nil {
+		return "", fmt.Errorf("r1fs: http backend not configured")
+	}
+	if src.Open == nil {
+		return "", fmt.Errorf("r1fs: upload source is required")
+	}
+	filename := resolveUploadName(opts)
+	if strings.TrimSpace(filename) == "" {
+		return "", fmt.Errorf("r1fs: filename or filepath is required")
+	}
+	metaBytes, err := json.Marshal(applyBodyJSON(opts))
+	if err != nil {
+		return "", fmt.Errorf("r1fs: encode multipart metadata: %w", err)
+	}
+	stream, err := newMultipartStream(src, filename, metaBytes)
+	if err != nil {
+		return "", err
+	}
+	defer stream.stop()
+
+	body, err := stream.body()
+	if err != nil {
+		return "", err
+	}
+	req := &httpx.Request{
+		Method:       http.MethodPost,
+		Path:         "add_file",
+		Header:       http.Header{"Content-Type": []string{stream.contentType()}},
+		Body:         body,
+		GetBody:      stream.body,
+		DisableRetry: !src.Replayable,
+	}
+	resp, err := b.client.Do(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	payloadBytes, err := httpx.ReadAllAndClose(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var response struct {
+		CID string `json:"cid"`
+	}
+	if err := ratio1api.DecodeResult(payloadBytes, &response); err != nil {
+		return "", fmt.Errorf("r1fs: decode add_file response: %w", err)
+	}
+	if strings.TrimSpace(response.CID) == "" {
+		return "", fmt.Errorf("r1fs: missing cid in add_file response")
+	}
+	return response.CID, nil
+}
@@ -0,0 +1,172 @@
+package r1fs_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1transport"
+)
+
+// newStreamingServer hashes the uploaded file part without buffering it and
+// fails the first failFirst attempts with 503.
+func newStreamingServer(t *testing.T, failFirst int32) (*testServer, *int32, *sync.Map) {
+	t.Helper()
+	var attempts int32
+	digests := &sync.Map{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/add_file" {
+			http.NotFound(w, r)
+			return
+		}
+		attempt := atomic.AddInt32(&attempts, 1)
+		mr, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var digest string
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if part.FormName() == "file" {
+				h := sha256.New()
+				if _, err := io.Copy(h, part); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				digest = hex.EncodeToString(h.Sum(nil))
+			}
+		}
+		digests.Store(attempt, digest)
+		if attempt <= failFirst {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{"cid": "Qm" + digest[:10]}})
+	})
+	return newLocalHTTPServer(t, handler), &attempts, digests
+}
+
+func fastRetries() r1transport.Option {
+	return r1transport.WithRetryPolicy(r1transport.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+}
+
+func TestAddFileRetriesSeekableReader(t *testing.T) {
+	srv, attempts, digests := newStreamingServer(t, 1)
+	defer srv.Close()
+	client, err := r1fs.New(srv.URL, fastRetries())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	payload := bytes.Repeat([]byte("ratio1"), 4096)
+	if _, err := client.AddFile(context.Background(), bytes.NewReader(payload), &r1fs.DataOptions{Filename: "a.bin"}); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	if got := atomic.LoadInt32(attempts); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+	sum := sha256.Sum256(payload)
+	want := hex.EncodeToString(sum[:])
+	for _, attempt := range []int32{1, 2} {
+		if got, _ := digests.Load(attempt); got != want {
+			t.Fatalf("attempt %d uploaded digest %v, want %s", attempt, got, want)
+		}
+	}
+}
+
+func TestAddFileFromReopensSource(t *testing.T) {
+	srv, attempts, _ := newStreamingServer(t, 1)
+	defer srv.Close()
+	client, err := r1fs.New(srv.URL, fastRetries())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var opened int32
+	open := func() (io.ReadCloser, error) {
+		atomic.AddInt32(&opened, 1)
+		return io.NopCloser(strings.NewReader("streamed")), nil
+	}
+	if _, err := client.AddFileFrom(context.Background(), open, &r1fs.DataOptions{Filename: "s.txt"}); err != nil {
+		t.Fatalf("AddFileFrom: %v", err)
+	}
+	if o, a := atomic.LoadInt32(&opened), atomic.LoadInt32(attempts); o != 2 || a != 2 {
+		t.Fatalf("expected source to be opened per attempt: opened=%d attempts=%d", o, a)
+	}
+}
+
+func TestAddFileDoesNotRetryOneShotReader(t *testing.T) {
+	srv, attempts, _ := newStreamingServer(t, 1)
+	defer srv.Close()
+	client, err := r1fs.New(srv.URL, fastRetries())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	oneShot := io.MultiReader(strings.NewReader("once"))
+	if _, err := client.AddFile(context.Background(), oneShot, &r1fs.DataOptions{Filename: "o.txt"}); err == nil {
+		t.Fatalf("expected error when a non-seekable upload fails")
+	}
+	if got := atomic.LoadInt32(attempts); got != 1 {
+		t.Fatalf("expected a single attempt, got %d", got)
+	}
+}
+
+type patternReader struct {
+	remaining int64
+}
+
+func (r *patternReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	for i := range p {
+		p[i] = byte(i)
+	}
+	r.remaining -= int64(len(p))
+	return len(p), nil
+}
+
+func TestAddFileStreamsLargePayload(t *testing.T) {
+	srv, _, _ := newStreamingServer(t, 0)
+	defer srv.Close()
+	client, err := r1fs.New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	const size = 64 << 20
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := client.AddFile(context.Background(), &patternReader{remaining: size}, &r1fs.DataOptions{Filename: "big.bin"}); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > size/4 {
+		t.Fatalf("upload allocated %d bytes for a %d byte payload", allocated, size)
+	}
+}
@@ -1,6 +1,10 @@
 package r1fs
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
 
 // DataOptions capture common optional parameters supported by R1FS uploads.
 type DataOptions struct {
@@ -40,13 +44,132 @@ type FileLocation struct {
 	Meta     map[string]any
 }
 
+// FileInfo describes a file opened through Client.Open or Client.GetFileTo.
+type FileInfo struct {
+	CID      string
+	Filename string
+	// Path is the local file that was read when the caller shares a
+	// filesystem with the upstream node; empty for streamed downloads.
+	Path string
+	// Size is the file size in bytes, or -1 when it is not known up front.
+	Size int64
+}
+
 // YAMLDocument captures YAML content decoded into the requested type.
 type YAMLDocument[T any] struct {
 	CID  string
 	Data T
 }
 
+// JSONDocument captures JSON content decoded into the requested type.
+type JSONDocument[T any] struct {
+	CID  string
+	Data T
+}
+
+// PickleDocument captures a decoded pickle object. Data holds plain Go values:
+// nil, bool, int64 or *big.Int, float64, string, []byte, []any,
+// map[string]any and *pickle.NDArray.
+type PickleDocument struct {
+	CID  string
+	Data any
+}
+
+// DirOptions configure Client.AddDir and Client.GetDir.
+type DirOptions struct {
+	// Secret is used for every file and for the manifest; GetDir needs the
+	// same secret to restore the tree.
+	Secret string
+	// Nonce is forwarded to the manifest upload.
+	Nonce *int
+	// Concurrency bounds the number of parallel transfers (default 4).
+	Concurrency int
+}
+
+// DirManifest lists the files uploaded by Client.AddDir. It is stored as a
+// JSON document and its CID identifies the whole tree.
+type DirManifest struct {
+	Version int        `json:"version"`
+	Files   []DirEntry `json:"files"`
+}
+
+// DirEntry describes one file of a DirManifest.
+type DirEntry struct {
+	Path   string      `json:"path"` // slash-separated, relative to the tree root
+	CID    string      `json:"cid"`
+	Size   int64       `json:"size"`
+	Mode   fs.FileMode `json:"mode"`   // permission bits
+	SHA256 string      `json:"sha256"` // hex digest of the content
+}
+
+// LargeFileOptions configure Client.AddLargeFile.
+type LargeFileOptions struct {
+	// Filename names the manifest and the chunk uploads.
+	Filename string
+	// Secret is used for every chunk and for the manifest.
+	Secret string
+	// Nonce is forwarded to the manifest upload.
+	Nonce *int
+	// ChunkSize is the size of every chunk but the last (default 16 MiB).
+	// Each chunk is buffered in memory while it is uploaded.
+	ChunkSize int64
+	// Progress records confirmed chunks so an interrupted upload resumes
+	// from the last one. Nil disables resuming.
+	Progress UploadProgress
+}
+
+// LargeFileManifest describes a file uploaded in chunks by
+// Client.AddLargeFile. It is stored as a JSON document.
+type LargeFileManifest struct {
+	Version   int              `json:"version"`
+	Filename  string           `json:"filename"`
+	Size      int64            `json:"size"`
+	ChunkSize int64            `json:"chunk_size"`
+	SHA256    string           `json:"sha256"` // hex digest of the whole file
+	Chunks    []LargeFileChunk `json:"chunks"`
+}
+
+// LargeFileChunk describes one chunk of a LargeFileManifest.
+type LargeFileChunk struct {
+	CID    string `json:"cid"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// UploadState is the progress of an AddLargeFile call: the chunks uploaded
+// so far, in order.
+type UploadState struct {
+	ChunkSize int64            `json:"chunk_size"`
+	Chunks    []LargeFileChunk `json:"chunks"`
+}
+
 var (
-	// ErrNotFound indicates the requested file is missing.
+	// ErrNotFound indicates the requested file is missing. With
+	// Client.StrictNotFound set it is returned for HTTP 404 responses and for
+	// the upstream "error" result string.
 	ErrNotFound = errors.New("r1fs: not found")
+
+	// ErrIntegrity indicates downloaded content does not hash to the requested
+	// CID. It is only reported when Client.VerifyContent is enabled.
+	ErrIntegrity = errors.New("r1fs: content does not match cid")
+
+	// ErrDecrypt indicates EncryptingBackend could not open a payload: it is
+	// not sealed, its key is unknown or it fails authentication.
+	ErrDecrypt = errors.New("r1fs: cannot decrypt payload")
 )
+
+// IntegrityError describes a CID mismatch detected by Client.VerifyContent.
+// It matches ErrIntegrity with errors.Is.
+type IntegrityError struct {
+	CID    string // CID that was requested
+	Actual string // CID computed from the received content
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("r1fs: content for cid %s hashes to %s", e.CID, e.Actual)
+}
+
+// Is reports whether target is ErrIntegrity.
+func (e *IntegrityError) Is(target error) bool {
+	return target == ErrIntegrity
+}
@@ -0,0 +1,94 @@
+package r1fs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/cid"
+)
+
+// newVerifier returns a hasher matching the layout of the requested CID.
+func newVerifier(id string, secret string) (*cid.Hasher, cid.CID, error) {
+	if strings.TrimSpace(secret) != "" {
+		return nil, cid.CID{}, fmt.Errorf("r1fs: cannot verify cid %s: content stored with a secret is addressed by its ciphertext", id)
+	}
+	want, err := cid.Parse(id)
+	if err != nil {
+		return nil, cid.CID{}, fmt.Errorf("r1fs: cannot verify cid %s: %w", id, err)
+	}
+	h, err := cid.NewHasher(&cid.Options{Version: want.Version})
+	if err != nil {
+		return nil, cid.CID{}, fmt.Errorf("r1fs: cannot verify cid %s: %w", id, err)
+	}
+	return h, want, nil
+}
+
+func checkSum(id string, h *cid.Hasher, want cid.CID) error {
+	if got := h.Sum(); !got.Equal(want) {
+		return &IntegrityError{CID: id, Actual: got.String()}
+	}
+	return nil
+}
+
+func verifyBytes(id string, secret string, data []byte) error {
+	return verifyReader(id, secret, bytes.NewReader(data))
+}
+
+func verifyFile(id string, secret string, path string) error {
+	if strings.TrimSpace(path) == "" {
+		return fmt.Errorf("r1fs: cannot verify cid %s: no file path reported", id)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("r1fs: cannot verify cid %s: %w", id, err)
+	}
+	defer f.Close()
+	return verifyReader(id, secret, f)
+}
+
+func verifyReader(id string, secret string, r io.Reader) error {
+	h, want, err := newVerifier(id, secret)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return fmt.Errorf("r1fs: verify cid %s: %w", id, err)
+	}
+	return checkSum(id, h, want)
+}
+
+// verifyingReader hashes content as it is read and reports an IntegrityError
+// in place of io.EOF when the stream does not match the requested CID.
+type verifyingReader struct {
+	io.ReadCloser
+	id   string
+	h    *cid.Hasher
+	want cid.CID
+	err  error
+}
+
+func newVerifyingReader(rc io.ReadCloser, id string, secret string) (io.ReadCloser, error) {
+	h, want, err := newVerifier(id, secret)
+	if err != nil {
+		return nil, err
+	}
+	return &verifyingReader{ReadCloser: rc, id: id, h: h, want: want}, nil
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.h.Write(p[:n])
+	if err == io.EOF {
+		if sumErr := checkSum(r.id, r.h, r.want); sumErr != nil {
+			err = sumErr
+		}
+		r.err = err
+	}
+	return n, err
+}
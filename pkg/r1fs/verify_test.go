@@ -0,0 +1,115 @@
+package r1fs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs"
+	"github.com/Ratio1/edge_sdk_go/pkg/r1fs/memfs"
+)
+
+// tamperingBackend serves altered content for every download.
+type tamperingBackend struct {
+	*memfs.Store
+	dir string
+}
+
+func (b *tamperingBackend) GetFileBase64(ctx context.Context, cid string, secret string) ([]byte, string, error) {
+	data, name, err := b.Store.GetFileBase64(ctx, cid, secret)
+	if err != nil {
+		return nil, "", err
+	}
+	return append(data, '!'), name, nil
+}
+
+func (b *tamperingBackend) GetFile(ctx context.Context, cid string, secret string) (*r1fs.FileLocation, error) {
+	data, name, err := b.GetFileBase64(ctx, cid, secret)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(b.dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, err
+	}
+	return &r1fs.FileLocation{Path: path, Filename: name}, nil
+}
+
+func TestVerifyContentAcceptsMatchingContent(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+	client.VerifyContent = true
+	ctx := context.Background()
+
+	cid, err := client.AddFile(ctx, strings.NewReader("trusted artifact"), &r1fs.DataOptions{Filename: "a.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	if _, _, err := client.GetFileBase64(ctx, cid, ""); err != nil {
+		t.Fatalf("GetFileBase64: %v", err)
+	}
+	if _, err := client.GetFile(ctx, cid, ""); err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+	var out bytes.Buffer
+	if _, err := client.GetFileTo(ctx, cid, "", &out); err != nil {
+		t.Fatalf("GetFileTo: %v", err)
+	}
+}
+
+func TestVerifyContentDetectsTampering(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(&tamperingBackend{Store: store, dir: t.TempDir()})
+	ctx := context.Background()
+
+	cid, err := client.AddFile(ctx, strings.NewReader("trusted artifact"), &r1fs.DataOptions{Filename: "a.txt"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	if _, _, err := client.GetFileBase64(ctx, cid, ""); err != nil {
+		t.Fatalf("verification should be opt-in: %v", err)
+	}
+
+	client.VerifyContent = true
+	_, _, err = client.GetFileBase64(ctx, cid, "")
+	var integrityErr *r1fs.IntegrityError
+	if !errors.Is(err, r1fs.ErrIntegrity) || !errors.As(err, &integrityErr) || integrityErr.CID != cid {
+		t.Fatalf("expected IntegrityError for %s, got %v", cid, err)
+	}
+	if _, err := client.GetFile(ctx, cid, ""); !errors.Is(err, r1fs.ErrIntegrity) {
+		t.Fatalf("GetFile: expected ErrIntegrity, got %v", err)
+	}
+
+	rc, _, err := client.Open(ctx, cid, "")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer rc.Close()
+	if _, err := io.ReadAll(rc); !errors.Is(err, r1fs.ErrIntegrity) {
+		t.Fatalf("Open: expected ErrIntegrity from Read, got %v", err)
+	}
+}
+
+func TestVerifyContentRejectsSecretContent(t *testing.T) {
+	store := memfs.New()
+	defer store.Close()
+	client := r1fs.NewWithBackend(store)
+	client.VerifyContent = true
+	ctx := context.Background()
+
+	cid, err := client.AddFile(ctx, strings.NewReader("secret"), &r1fs.DataOptions{Filename: "s.txt", Secret: "k"})
+	if err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	_, _, err = client.GetFileBase64(ctx, cid, "k")
+	if err == nil || errors.Is(err, r1fs.ErrIntegrity) {
+		t.Fatalf("expected an unverifiable-content error, got %v", err)
+	}
+}
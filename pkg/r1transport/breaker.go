@@ -0,0 +1,34 @@
+package r1transport
+
+import "github.com/Ratio1/edge_sdk_go/internal/httpx"
+
+// ErrCircuitOpen is returned without contacting the server while the circuit
+// for a route is open. When the circuit opens while a request is retrying, the
+// returned error wraps both ErrCircuitOpen and the last HTTPError.
+var ErrCircuitOpen = httpx.ErrCircuitOpen
+
+// CircuitState is the state of the circuit guarding a route.
+type CircuitState = httpx.CircuitState
+
+// Circuit states reported to BreakerPolicy.OnStateChange.
+const (
+	CircuitClosed   = httpx.CircuitClosed
+	CircuitOpen     = httpx.CircuitOpen
+	CircuitHalfOpen = httpx.CircuitHalfOpen
+)
+
+// BreakerPolicy configures failure thresholds, the open timeout and the
+// state-change callback of the circuit breaker.
+type BreakerPolicy = httpx.BreakerPolicy
+
+// DefaultBreakerPolicy returns the values applied for zero BreakerPolicy
+// fields.
+func DefaultBreakerPolicy() BreakerPolicy {
+	return httpx.DefaultBreakerPolicy
+}
+
+// WithCircuitBreaker enables a circuit breaker with one circuit per API route,
+// so an unavailable upstream fails fast instead of waiting through retries.
+func WithCircuitBreaker(policy BreakerPolicy) Option {
+	return httpx.WithCircuitBreaker(policy)
+}
@@ -0,0 +1,89 @@
+// Package r1transport exposes the HTTP transport options shared by the cstore
+// and r1fs clients. The underlying implementation lives in an internal package;
+// the aliases below let callers outside this module tune timeouts, headers,
+// authentication and retry behaviour without forking the SDK.
+package r1transport
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Ratio1/edge_sdk_go/internal/httpx"
+)
+
+// Option configures the HTTP transport used by the cstore and r1fs clients.
+type Option = httpx.Option
+
+// RetryPolicy controls the retry behaviour for transient failures.
+type RetryPolicy = httpx.RetryPolicy
+
+// MaxRetryAfter is the longest Retry-After delay honoured when
+// RetryPolicy.MaxElapsed is zero; longer requests end the retries.
+const MaxRetryAfter = httpx.MaxRetryAfter
+
+// HTTPError describes a failed request: a non-2xx response, or the transport
+// error left after retries were exhausted.
+type HTTPError = httpx.HTTPError
+
+// DefaultRetryPolicy returns the retry strategy applied when no override is set.
+func DefaultRetryPolicy() RetryPolicy {
+	return httpx.DefaultRetryPolicy
+}
+
+// WithHTTPClient overrides the HTTP client used for outbound requests, for
+// example to configure timeouts or a custom http.RoundTripper.
+func WithHTTPClient(h *http.Client) Option {
+	return httpx.WithHTTPClient(h)
+}
+
+// WithHeaders assigns default headers added to every request.
+func WithHeaders(h http.Header) Option {
+	return httpx.WithHeaders(h)
+}
+
+// WithRetryPolicy overrides the default retry configuration.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return httpx.WithRetryPolicy(policy)
+}
+
+// Authenticator adds credentials to every request attempt, including retries.
+type Authenticator = httpx.Authenticator
+
+// AuthenticatorFunc adapts a function into an Authenticator.
+type AuthenticatorFunc = httpx.AuthenticatorFunc
+
+// TokenSource returns the bearer token to send with a request.
+type TokenSource = httpx.TokenSource
+
+// TokenSourceFunc adapts a function into a TokenSource.
+type TokenSourceFunc = httpx.TokenSourceFunc
+
+// HMACSigner signs requests with HMAC-SHA256 over the method, path, query,
+// timestamp and body hash.
+type HMACSigner = httpx.HMACSigner
+
+// UnsignedPayload is the content hash HMACSigner reports for streamed bodies.
+const UnsignedPayload = httpx.UnsignedPayload
+
+// WithAuthenticator installs an Authenticator invoked for every request
+// attempt, so rotating tokens and signatures are recomputed on retries.
+func WithAuthenticator(a Authenticator) Option {
+	return httpx.WithAuthenticator(a)
+}
+
+// BearerAuth sends "Authorization: Bearer <token>" with a token from src.
+func BearerAuth(src TokenSource) Authenticator {
+	return httpx.BearerAuth(src)
+}
+
+// StaticToken returns a TokenSource that always yields token.
+func StaticToken(token string) TokenSource {
+	return httpx.StaticToken(token)
+}
+
+// CachedToken returns a TokenSource that reuses the token returned by fetch
+// until shortly before its expiry.
+func CachedToken(fetch func(ctx context.Context) (token string, expiry time.Time, err error)) TokenSource {
+	return httpx.CachedToken(fetch)
+}
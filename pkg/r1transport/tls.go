@@ -0,0 +1,52 @@
+package r1transport
+
+import (
+	"fmt"
+
+	"github.com/Ratio1/edge_sdk_go/internal/httpx"
+)
+
+// Environment variables read by TLSConfigFromEnv.
+const (
+	EnvTLSCert       = httpx.EnvTLSCert       // client certificate (PEM file)
+	EnvTLSKey        = httpx.EnvTLSKey        // client private key (PEM file)
+	EnvTLSCA         = httpx.EnvTLSCA         // CA bundle (PEM file)
+	EnvTLSServerName = httpx.EnvTLSServerName // server name override
+	EnvTLSMinVersion = httpx.EnvTLSMinVersion // "1.2" or "1.3"
+)
+
+// TLSConfig describes client certificates, CA bundle, server name and minimum
+// version for HTTPS node APIs.
+type TLSConfig = httpx.TLSConfig
+
+// WithTLS applies cfg to the HTTP transport. Files are loaded when the client
+// is constructed and errors are returned by the constructor.
+func WithTLS(cfg TLSConfig) Option {
+	return httpx.WithTLS(cfg)
+}
+
+// ParseTLSVersion converts "1.2", "TLS1.3" and similar into a crypto/tls
+// version constant.
+func ParseTLSVersion(s string) (uint16, error) {
+	return httpx.ParseTLSVersion(s)
+}
+
+// TLSConfigFromEnv reads the EE_R1_TLS_* variables. It returns nil when none
+// of them is set.
+func TLSConfigFromEnv() (*TLSConfig, error) {
+	cfg, err := httpx.TLSConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("r1transport: %w", err)
+	}
+	return cfg, nil
+}
+
+// EnvOptions returns the transport options configured through environment
+// variables, to be applied before caller supplied options.
+func EnvOptions() ([]Option, error) {
+	cfg, err := TLSConfigFromEnv()
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+	return []Option{WithTLS(*cfg)}, nil
+}